@@ -1,23 +1,25 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
-	"log"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
 )
 
-func Run() {
+// Run prompts for credentials and saves them. It returns an error when the
+// prompt fails or the user cancels without authenticating.
+func Run() error {
 	p := tea.NewProgram(initialModel())
 	finalModel, err := p.Run()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	m := finalModel.(model)
-	if m.authenticated {
-		fmt.Println(config.SuccessStyle.Render("Authentication successful!"))
-	} else {
-		fmt.Println("Authentication cancelled.")
+	if !m.authenticated {
+		return errors.New("authentication cancelled")
 	}
+	fmt.Println(config.SuccessStyle.Render("Authentication successful!"))
+	return nil
 }
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+)
+
+// defaultCacheTTL is how long a successful validation is trusted before the
+// server is asked again. GOF_AUTH_TTL (a Go duration, "0" disables) overrides it.
+const defaultCacheTTL = 24 * time.Hour
+
+// validationCache remembers the last successful validation. It stores a hash
+// of server, email and API key, never the key itself.
+type validationCache struct {
+	Key         string    `json:"key"`
+	ValidatedAt time.Time `json:"validated_at"`
+}
+
+func cacheTTL() time.Duration {
+	if v := os.Getenv("GOF_AUTH_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return defaultCacheTTL
+}
+
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofast", "auth.json"), nil
+}
+
+func cacheKey(c Config) string {
+	sum := sha256.Sum256([]byte(config.ServerURL() + "\x00" + c.Email + "\x00" + c.ApiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedValidation returns when these credentials were last validated.
+func cachedValidation(c Config) (time.Time, bool) {
+	path, err := cachePath()
+	if err != nil {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	var vc validationCache
+	if err := json.Unmarshal(data, &vc); err != nil || vc.Key != cacheKey(c) {
+		return time.Time{}, false
+	}
+	return vc.ValidatedAt, true
+}
+
+// rememberValidation records a successful validation. Failing to write the
+// cache only costs a request next time, so errors are ignored.
+func rememberValidation(c Config) {
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(validationCache{Key: cacheKey(c), ValidatedAt: time.Now()})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
+
+func forgetValidation() {
+	if path, err := cachePath(); err == nil {
+		_ = os.Remove(path)
+	}
+}
@@ -1,16 +1,32 @@
 package auth
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"net/mail"
+	"net/url"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/httpx"
+)
+
+// Environment variables that provide credentials without 'gof auth'. When
+// both are set they take precedence over the saved config file.
+const (
+	EnvEmail  = "GOF_EMAIL"
+	EnvApiKey = "GOF_API_KEY"
+)
+
+// Credential sources.
+const (
+	SourceEnv      = "env"
+	SourceFile     = "file"
+	SourceOffline  = "offline"
+	SourceTemplate = "template"
 )
 
 type Config struct {
@@ -20,14 +36,8 @@ type Config struct {
 
 func checkConfig(email string, apiKey string) tea.Cmd {
 	return func() tea.Msg {
-		if email == "" {
-			return errMsg{nil, "Email address is required"}
-		}
-		if _, err := mail.ParseAddress(email); err != nil {
-			return errMsg{err, "Invalid email address format"}
-		}
-		if apiKey == "" {
-			return errMsg{nil, "API key is required"}
+		if err := validateInput(email, apiKey); err != nil {
+			return errMsg{nil, err.Error()}
 		}
 		err := saveToConfig(email, apiKey)
 		if err != nil {
@@ -41,110 +51,167 @@ func checkConfig(email string, apiKey string) tea.Cmd {
 	}
 }
 
-func CheckAuthentication() (string, string, error) {
-	if os.Getenv("TEST") == "true" {
-		return "test@gofast.local", "test-api-key", nil
+func validateInput(email string, apiKey string) error {
+	if email == "" {
+		return errors.New("Email address is required")
 	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		return errors.New("Invalid email address format")
+	}
+	if apiKey == "" {
+		return errors.New("API key is required")
+	}
+	return nil
+}
 
-	path, err := os.UserConfigDir()
+// Login validates the credentials against the server and saves them, for
+// non-interactive use ('gof auth --email ... --api-key-stdin').
+func Login(email string, apiKey string) error {
+	if err := validateInput(email, apiKey); err != nil {
+		return err
+	}
+	if err := validateConfig(email, apiKey); err != nil {
+		return fmt.Errorf("authentication failed, please check your email and API key: %w", err)
+	}
+	if err := saveToConfig(email, apiKey); err != nil {
+		return err
+	}
+	rememberValidation(Config{Email: email, ApiKey: apiKey})
+	return nil
+}
+
+// Logout removes the saved credentials. It reports whether there was
+// anything to remove.
+func Logout() (bool, error) {
+	st, err := chooseStore()
 	if err != nil {
-		return "", "", err
+		return false, err
 	}
-	configPath := path + "/gofast.json"
-	jsonFile, err := os.OpenFile(configPath, os.O_RDWR, 0666)
+	forgetValidation()
+	removed, err := st.delete()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", "", errors.New("config file not found. Please run 'gof auth'")
-		}
-		return "", "", err
+		return removed, err
 	}
-	defer func() {
-		closeErr := jsonFile.Close()
-		if closeErr != nil {
-			fmt.Printf("error closing config file: %v\n", closeErr)
+	if path, err := legacyPath(); err == nil {
+		if err := os.Remove(path); err == nil {
+			removed = true
 		}
-	}()
+	}
+	return removed, nil
+}
 
-	data, err := io.ReadAll(jsonFile)
+// StoreName describes where 'gof auth' saves credentials.
+func StoreName() string {
+	st, err := chooseStore()
 	if err != nil {
-		return "", "", err
+		return err.Error()
 	}
+	return st.name()
+}
 
-	if len(data) == 0 {
-		return "", "", errors.New("config file is empty. Please run 'gof auth'")
+// Current returns the credentials gof would use and where they come from,
+// without validating them against the server.
+func Current() (Config, string, error) {
+	if config.TemplateSource() != "" {
+		return Config{}, SourceTemplate, nil
+	}
+	if config.Offline() {
+		return Config{}, SourceOffline, nil
+	}
+	email, apiKey := os.Getenv(EnvEmail), os.Getenv(EnvApiKey)
+	if email != "" || apiKey != "" {
+		if email == "" || apiKey == "" {
+			return Config{}, SourceEnv, fmt.Errorf("%s and %s must both be set", EnvEmail, EnvApiKey)
+		}
+		return Config{Email: email, ApiKey: apiKey}, SourceEnv, nil
 	}
+	c, err := readConfig()
+	return c, SourceFile, err
+}
 
-	var c Config
-	err = json.Unmarshal(data, &c)
+// Verify checks credentials against the server, bypassing the cache.
+func Verify(c Config) error {
+	if err := validateConfig(c.Email, c.ApiKey); err != nil {
+		return err
+	}
+	rememberValidation(c)
+	return nil
+}
+
+// CheckAuthentication returns the credentials to download templates with.
+// A validation younger than the cache TTL is trusted without asking the
+// server; when the server cannot be reached, an older successful validation
+// of the same credentials is accepted too, so local-only commands keep
+// working offline. In offline mode or with a custom template no credentials
+// are needed and both strings are empty.
+func CheckAuthentication() (string, string, error) {
+	c, source, err := Current()
 	if err != nil {
-		return "", "", errors.New("failed to parse config file. It might be corrupted. Please run 'gof auth'")
+		return "", "", err
+	}
+	if source == SourceOffline || source == SourceTemplate {
+		return "", "", nil
 	}
 
-	if c.Email == "" || c.ApiKey == "" {
-		return "", "", errors.New("email or API key not found in config. Please run 'gof auth'")
+	validatedAt, cached := cachedValidation(c)
+	if cached && time.Since(validatedAt) < cacheTTL() {
+		return c.Email, c.ApiKey, nil
 	}
 
 	err = validateConfig(c.Email, c.ApiKey)
+	var netErr *httpx.NetworkError
+	if err != nil && cached && errors.As(err, &netErr) {
+		return c.Email, c.ApiKey, nil
+	}
 	if err != nil {
+		if source == SourceEnv {
+			return "", "", fmt.Errorf("authentication failed: %w. Please check %s and %s", err, EnvEmail, EnvApiKey)
+		}
 		return "", "", fmt.Errorf("authentication failed: %w. Please run 'gof auth'", err)
 	}
 
+	rememberValidation(c)
 	return c.Email, c.ApiKey, nil
 }
 
-func saveToConfig(email string, apiKey string) error {
-	path, err := os.UserConfigDir()
+// readConfig loads the saved credentials, migrating a legacy plaintext
+// config on first use.
+func readConfig() (Config, error) {
+	st, err := chooseStore()
 	if err != nil {
-		return fmt.Errorf("error getting user config directory: %w", err)
+		return Config{}, err
 	}
-	config := path + "/gofast.json"
-	jsonFile, err := os.OpenFile(config, os.O_CREATE|os.O_RDWR, 0666)
-	if err != nil {
-		return fmt.Errorf("error opening config file: %w", err)
+	c, err := st.load()
+	if errors.Is(err, errNoCredentials) {
+		c, err = migrateLegacy(st)
 	}
-	defer func() {
-		closeErr := jsonFile.Close()
-		if closeErr != nil {
-			fmt.Printf("error closing response body: %v\n", closeErr)
-		}
-	}()
-	data, err := io.ReadAll(jsonFile)
-	if err != nil {
-		return fmt.Errorf("error reading config file: %w", err)
+	if errors.Is(err, errNoCredentials) {
+		return Config{}, errors.New("no saved credentials. Please run 'gof auth' or set GOF_EMAIL and GOF_API_KEY")
 	}
-	var c Config
-	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func saveToConfig(email string, apiKey string) error {
+	st, err := chooseStore()
 	if err != nil {
-		_ = jsonFile.Truncate(0)
-		c = Config{}
+		return err
 	}
-	c.Email = email
-	c.ApiKey = apiKey
-	data, err = json.Marshal(c)
-	if err != nil {
-		return fmt.Errorf("error marshalling config: %w", err)
+	if err := st.save(Config{Email: email, ApiKey: apiKey}); err != nil {
+		return err
 	}
-	_ = jsonFile.Truncate(0)
-	_, err = jsonFile.WriteAt(data, 0)
-	if err != nil {
-		return fmt.Errorf("error writing to config file: %w", err)
+	// Saving supersedes a legacy plaintext file that was never migrated.
+	if path, err := legacyPath(); err == nil {
+		_ = os.Remove(path)
 	}
 	return nil
 }
 
 func validateConfig(email string, apiKey string) error {
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", config.SERVER_URL+"/repo", nil)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
-	}
-	req.Header.Add("Authorization", "Bearer "+apiKey)
-	q := req.URL.Query()
-	q.Add("email", email)
-	req.URL.RawQuery = q.Encode()
-	resp, err := client.Do(req)
+	header := http.Header{}
+	header.Add("Authorization", "Bearer "+apiKey)
+	resp, err := httpx.Get(config.ServerURL()+"/repo?"+url.Values{"email": {email}}.Encode(), header)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
+		return err
 	}
 	defer func() {
 		closeErr := resp.Body.Close()
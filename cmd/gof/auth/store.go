@@ -0,0 +1,318 @@
+package auth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// errNoCredentials is returned by a store that has nothing saved.
+var errNoCredentials = errors.New("no saved credentials")
+
+// keyringService and keyringAccount identify the credentials entry in the OS
+// secret store.
+const (
+	keyringService = "gofast-cli"
+	keyringAccount = "default"
+)
+
+// store keeps the credentials between runs. GOF_KEYRING selects the backend:
+// "keychain" (macOS security), "secret-service" (Linux secret-tool), "file",
+// or "auto" (default: the OS secret store when available, else the file).
+type store interface {
+	name() string
+	load() (Config, error)
+	save(c Config) error
+	delete() (bool, error)
+}
+
+func chooseStore() (store, error) {
+	switch mode := os.Getenv("GOF_KEYRING"); mode {
+	case "", "auto":
+		file, err := newFileStore()
+		if err != nil {
+			return nil, err
+		}
+		if runtime.GOOS == "darwin" && hasTool("security") {
+			return autoStore{keyring: keychainStore{}, file: file}, nil
+		}
+		if runtime.GOOS == "linux" && hasTool("secret-tool") && os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" {
+			return autoStore{keyring: secretServiceStore{}, file: file}, nil
+		}
+		return file, nil
+	case "keychain":
+		return keychainStore{}, nil
+	case "secret-service":
+		return secretServiceStore{}, nil
+	case "file":
+		return newFileStore()
+	default:
+		return nil, fmt.Errorf("unknown GOF_KEYRING %q (use auto, keychain, secret-service or file)", mode)
+	}
+}
+
+// autoStore prefers the OS secret store and falls back to the file when the
+// secret store is unusable (locked keyring, no agent on a headless box).
+type autoStore struct {
+	keyring store
+	file    fileStore
+}
+
+func (s autoStore) name() string { return s.keyring.name() }
+
+func (s autoStore) load() (Config, error) {
+	c, err := s.keyring.load()
+	if err == nil {
+		return c, nil
+	}
+	return s.file.load()
+}
+
+func (s autoStore) save(c Config) error {
+	if err := s.keyring.save(c); err != nil {
+		return s.file.save(c)
+	}
+	// Do not leave an older copy behind in the fallback file.
+	_, _ = s.file.delete()
+	return nil
+}
+
+func (s autoStore) delete() (bool, error) {
+	removed, err := s.keyring.delete()
+	fileRemoved, fileErr := s.file.delete()
+	if err == nil {
+		err = fileErr
+	}
+	return removed || fileRemoved, err
+}
+
+func hasTool(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// fileStore keeps the credentials in UserConfigDir/gofast/credentials.json,
+// readable only by the user.
+type fileStore struct {
+	path string
+}
+
+func newFileStore() (fileStore, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return fileStore{}, fmt.Errorf("error getting user config directory: %w", err)
+	}
+	return fileStore{path: filepath.Join(dir, "gofast", "credentials.json")}, nil
+}
+
+func (s fileStore) name() string { return "file " + s.path }
+
+func (s fileStore) load() (Config, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, errNoCredentials
+		}
+		return Config{}, err
+	}
+	return decodeCredentials(data)
+}
+
+func (s fileStore) save(c Config) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling credentials: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("error creating credentials directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	// CreateTemp already uses 0600; chmod keeps that true on odd umasks.
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("error writing credentials: %w", err)
+	}
+	return nil
+}
+
+func (s fileStore) delete() (bool, error) {
+	if err := os.Remove(s.path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// keychainStore keeps the credentials in the macOS login keychain.
+type keychainStore struct{}
+
+func (keychainStore) name() string { return "macOS keychain" }
+
+func (keychainStore) load() (Config, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// Exit status 44: the item could not be found.
+			if exitErr.ExitCode() == 44 {
+				return Config{}, errNoCredentials
+			}
+		}
+		return Config{}, fmt.Errorf("reading keychain: %w", err)
+	}
+	return decodeCredentials(out)
+}
+
+func (keychainStore) save(c Config) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling credentials: %w", err)
+	}
+	// The secret must not appear in argv, where any local user can read it
+	// from the process list: security -i reads the command from stdin, and
+	// -X takes the password hex-encoded so no quoting is involved.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l \"GoFast CLI\" -X %s\n",
+		keyringService, keyringAccount, hex.EncodeToString(data)))
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("writing keychain: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	// In interactive mode a failed command does not set the exit status;
+	// add-generic-password prints nothing on success.
+	if msg := strings.TrimSpace(string(out)); msg != "" {
+		return fmt.Errorf("writing keychain: %s", msg)
+	}
+	return nil
+}
+
+func (keychainStore) delete() (bool, error) {
+	err := exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", keyringAccount).Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return false, nil
+		}
+		return false, fmt.Errorf("deleting keychain item: %w", err)
+	}
+	return true, nil
+}
+
+// secretServiceStore keeps the credentials in the freedesktop secret service
+// (GNOME Keyring, KWallet) through secret-tool.
+type secretServiceStore struct{}
+
+func (secretServiceStore) name() string { return "secret service" }
+
+func (secretServiceStore) load() (Config, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", keyringAccount).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		// secret-tool exits 1 with no output when nothing matches.
+		if errors.As(err, &exitErr) && len(out) == 0 && len(exitErr.Stderr) == 0 {
+			return Config{}, errNoCredentials
+		}
+		return Config{}, fmt.Errorf("reading secret service: %w", err)
+	}
+	if len(out) == 0 {
+		return Config{}, errNoCredentials
+	}
+	return decodeCredentials(out)
+}
+
+func (secretServiceStore) save(c Config) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshalling credentials: %w", err)
+	}
+	cmd := exec.Command("secret-tool", "store", "--label=GoFast CLI", "service", keyringService, "account", keyringAccount)
+	cmd.Stdin = strings.NewReader(string(data))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("writing secret service: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (s secretServiceStore) delete() (bool, error) {
+	if _, err := s.load(); errors.Is(err, errNoCredentials) {
+		return false, nil
+	}
+	if err := exec.Command("secret-tool", "clear", "service", keyringService, "account", keyringAccount).Run(); err != nil {
+		return false, fmt.Errorf("deleting secret: %w", err)
+	}
+	return true, nil
+}
+
+func decodeCredentials(data []byte) (Config, error) {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return Config{}, errNoCredentials
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Config{}, errors.New("failed to parse saved credentials. They might be corrupted. Please run 'gof auth'")
+	}
+	if c.Email == "" || c.ApiKey == "" {
+		return Config{}, errors.New("email or API key not found in saved credentials. Please run 'gof auth'")
+	}
+	return c, nil
+}
+
+// legacyPath is where gof up to v2.17 saved credentials: plaintext JSON with
+// mode 0666, named like the project config.
+func legacyPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofast.json"), nil
+}
+
+// migrateLegacy moves credentials from the legacy file into s and removes
+// the file. It returns errNoCredentials when there is no legacy file.
+func migrateLegacy(s store) (Config, error) {
+	path, err := legacyPath()
+	if err != nil {
+		return Config{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, errNoCredentials
+		}
+		return Config{}, err
+	}
+	c, err := decodeCredentials(data)
+	if err != nil {
+		return Config{}, err
+	}
+	if err := s.save(c); err != nil {
+		return Config{}, fmt.Errorf("migrating credentials from %s: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return Config{}, fmt.Errorf("removing old credentials file %s: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Moved credentials from %s to %s.\n", path, s.name())
+	return c, nil
+}
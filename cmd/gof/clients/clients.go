@@ -1,10 +1,17 @@
 package clients
 
-import "github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+import (
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+)
 
 const (
 	Svelte   = "svelte"
 	Tanstack = "tanstack"
+	Next     = "next"
+	Vue      = "vue"
+	Htmx     = "htmx"
 )
 
 type Spec struct {
@@ -13,6 +20,9 @@ type Spec struct {
 	ServiceDir           string
 	ComposeFile          string
 	Port                 string
+	StartTarget          string // Makefile target starting the app with this client
+	Node                 bool   // built with npm; false for the Go htmx client
+	ModelsRouteSubpath   string
 	PaymentsRouteSubpath string
 	FilesRouteSubpath    string
 	EmailsRouteSubpath   string
@@ -25,6 +35,9 @@ var specs = map[string]Spec{
 		ServiceDir:           "service-svelte",
 		ComposeFile:          "docker-compose.svelte.yml",
 		Port:                 "3000",
+		StartTarget:          "starts",
+		Node:                 true,
+		ModelsRouteSubpath:   "src/routes/(app)/models",
 		PaymentsRouteSubpath: "src/routes/(app)/payments",
 		FilesRouteSubpath:    "src/routes/(app)/files",
 		EmailsRouteSubpath:   "src/routes/(app)/emails",
@@ -35,10 +48,51 @@ var specs = map[string]Spec{
 		ServiceDir:           "service-tanstack",
 		ComposeFile:          "docker-compose.tanstack.yml",
 		Port:                 "3000",
+		StartTarget:          "startt",
+		Node:                 true,
+		ModelsRouteSubpath:   "src/routes/_layout/models",
 		PaymentsRouteSubpath: "src/routes/_layout/payments",
 		FilesRouteSubpath:    "src/routes/_layout/files.tsx",
 		EmailsRouteSubpath:   "src/routes/_layout/emails.tsx",
 	},
+	Next: {
+		Name:                 Next,
+		DisplayName:          "Next.js",
+		ServiceDir:           "service-next",
+		ComposeFile:          "docker-compose.next.yml",
+		Port:                 "3000",
+		StartTarget:          "startn",
+		Node:                 true,
+		ModelsRouteSubpath:   "src/app/(app)/models",
+		PaymentsRouteSubpath: "src/app/(app)/payments",
+		FilesRouteSubpath:    "src/app/(app)/files",
+		EmailsRouteSubpath:   "src/app/(app)/emails",
+	},
+	Vue: {
+		Name:                 Vue,
+		DisplayName:          "Vue",
+		ServiceDir:           "service-vue",
+		ComposeFile:          "docker-compose.vue.yml",
+		Port:                 "3000",
+		StartTarget:          "startv",
+		Node:                 true,
+		ModelsRouteSubpath:   "app/pages/models",
+		PaymentsRouteSubpath: "app/pages/payments",
+		FilesRouteSubpath:    "app/pages/files.vue",
+		EmailsRouteSubpath:   "app/pages/emails.vue",
+	},
+	Htmx: {
+		Name:                 Htmx,
+		DisplayName:          "htmx",
+		ServiceDir:           "service-htmx",
+		ComposeFile:          "docker-compose.htmx.yml",
+		Port:                 "3000",
+		StartTarget:          "starth",
+		ModelsRouteSubpath:   "templates/models",
+		PaymentsRouteSubpath: "templates/payments",
+		FilesRouteSubpath:    "templates/files.html",
+		EmailsRouteSubpath:   "templates/emails.html",
+	},
 }
 
 func SpecFor(name string) (Spec, bool) {
@@ -50,9 +104,22 @@ func All() []Spec {
 	return []Spec{
 		specs[Svelte],
 		specs[Tanstack],
+		specs[Next],
+		specs[Vue],
+		specs[Htmx],
 	}
 }
 
+// Names lists the client names for help and error messages, e.g.
+// "svelte, tanstack, next, vue, htmx".
+func Names() string {
+	var names []string
+	for _, spec := range All() {
+		names = append(names, spec.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 func Enabled(cfg *config.Config) []Spec {
 	if cfg == nil {
 		return nil
@@ -71,3 +138,13 @@ func Enabled(cfg *config.Config) []Spec {
 func HasAny(cfg *config.Config) bool {
 	return len(Enabled(cfg)) > 0
 }
+
+// NeedsNode reports whether an enabled client is built with npm.
+func NeedsNode(cfg *config.Config) bool {
+	for _, spec := range Enabled(cfg) {
+		if spec.Node {
+			return true
+		}
+	}
+	return false
+}
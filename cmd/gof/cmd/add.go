@@ -17,14 +17,16 @@ func init() {
 	addCmd.AddCommand(addPostmarkCmd)
 }
 
-func formatEnabledClients() error {
-	cfg, err := config.ParseConfig()
-	if err != nil {
-		return err
-	}
+// integrationEnvVars are the environment variables each integration reads.
+var integrationEnvVars = map[string][]string{
+	"stripe":   {"STRIPE_API_KEY", "STRIPE_WEBHOOK_SECRET", "STRIPE_PRICE_ID_BASIC", "STRIPE_PRICE_ID_PRO"},
+	"s3":       {"S3_ACCESS_KEY_ID", "S3_SECRET_ACCESS_KEY", "S3_ENDPOINT", "BUCKET_NAME"},
+	"postmark": {"POSTMARK_API_KEY", "EMAIL_FROM"},
+}
 
+func formatEnabledClients(cfg *config.Config) error {
 	for _, client := range clients.Enabled(cfg) {
-		if err := formatClientProject(client.Name); err != nil {
+		if err := formatClientProject(client.Name, "."); err != nil {
 			return err
 		}
 	}
@@ -56,65 +58,75 @@ After running this command:
 3. Run 'make migrate' to create the subscriptions table
 4. Configure Stripe environment variables in your .env file
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
 		// Ensure we are inside a valid gofast project
-		if _, err := config.ParseConfig(); err != nil {
-			cmd.Printf("%v\n", err)
-			return
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+		if con.HasIntegration("stripe") {
+			return usageErr("Stripe is already added (listed in %s)", config.ConfigFileName)
+		}
+		if err := preflightMarkers(cmd, servicePackage(coreService)); err != nil {
+			return err
+		}
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		cmd.Println("")
 		cmd.Println("Adding Stripe payment integration...")
 
-		if err := integrations.StripeAdd(email, apiKey); err != nil {
-			cmd.Printf("Error adding Stripe: %v\n", err)
-			return
+		if err := integrations.StripeAdd(email, apiKey, version, con.Config); err != nil {
+			return genErr("adding Stripe: %w", err)
+		}
+		// Record the integration before formatting, so a formatter failure
+		// leaves a project that knows its Stripe code and migration are in.
+		con.AddIntegration("stripe")
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %w", config.ConfigFileName, err)
 		}
 
 		// Format Go code
 		gofmtCmd := exec.Command("go", "fmt", "./...")
 		gofmtCmd.Dir = "app/service-core"
 		if output, err := gofmtCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: go fmt failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "go fmt failed: %v\nOutput: %s", err, output)
 		}
 
-		if err := config.AddIntegration("stripe"); err != nil {
-			cmd.Printf("Error updating config: %v\n", err)
-			return
-		}
-		if err := formatEnabledClients(); err != nil {
-			cmd.Printf("Error formatting client after Stripe add: %v\n", err)
-			return
+		if err := formatEnabledClients(con.Config); err != nil {
+			return genErr("formatting client after Stripe add: %w", err)
 		}
 
 		cmd.Println("")
 		cmd.Println(config.SuccessStyle.Render("Stripe integration added successfully!"))
 		cmd.Println("")
-		if cfg, err := config.ParseConfig(); err == nil && clients.HasAny(cfg) {
+		if clients.HasAny(con.Config) {
 			cmd.Println("Add this route to your client navigation:")
-			cmd.Printf("  %s\n", config.SuccessStyle.Render("/payments"))
+			printRoute(cmd, "/payments")
 			cmd.Println("")
 		}
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s to regenerate proto code\n", config.SuccessStyle.Render("'make gen'"))
-		cmd.Printf("  2. Run %s to regenerate SQL queries\n", config.SuccessStyle.Render("'make sql'"))
-		cmd.Printf("  3. Run %s to format generated code\n", config.SuccessStyle.Render("'make format'"))
-		cmd.Printf("  4. Run %s to apply migrations\n", config.SuccessStyle.Render("'make migrate'"))
+		runStep(cmd, 1, "make gen", "to regenerate proto code")
+		runStep(cmd, 2, "make sql", "to regenerate SQL queries")
+		runStep(cmd, 3, "make format", "to format generated code")
+		runStep(cmd, 4, "make migrate", "to apply migrations")
 		cmd.Println("  5. Add environment variables to docker-compose.yml:")
-		cmd.Println("     - STRIPE_API_KEY")
-		cmd.Println("     - STRIPE_WEBHOOK_SECRET")
-		cmd.Println("     - STRIPE_PRICE_ID_BASIC")
-		cmd.Println("     - STRIPE_PRICE_ID_PRO")
+		for _, v := range integrationEnvVars["stripe"] {
+			printEnvVar(cmd, v)
+		}
 		cmd.Println("  6. Add to GitHub secrets/variables:")
 		cmd.Println("     Secrets: STRIPE_API_KEY, STRIPE_WEBHOOK_SECRET")
 		cmd.Println("     Variables: STRIPE_PRICE_ID_BASIC, STRIPE_PRICE_ID_PRO")
 		cmd.Println("")
+		return nil
 	},
 }
 
@@ -136,65 +148,75 @@ After running this command:
 3. Run 'make migrate' to create the files table
 4. Configure S3 environment variables in your .env file
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
 		// Ensure we are inside a valid gofast project
-		if _, err := config.ParseConfig(); err != nil {
-			cmd.Printf("%v\n", err)
-			return
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+		if con.HasIntegration("s3") {
+			return usageErr("S3 is already added (listed in %s)", config.ConfigFileName)
+		}
+		if err := preflightMarkers(cmd, servicePackage(coreService)); err != nil {
+			return err
+		}
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		cmd.Println("")
 		cmd.Println("Adding S3 file storage integration...")
 
-		if err := integrations.S3Add(email, apiKey); err != nil {
-			cmd.Printf("Error adding S3: %v\n", err)
-			return
+		if err := integrations.S3Add(email, apiKey, version, con.Config); err != nil {
+			return genErr("adding S3: %w", err)
+		}
+		// Record the integration before formatting, so a formatter failure
+		// leaves a project that knows its S3 code and migration are in.
+		con.AddIntegration("s3")
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %w", config.ConfigFileName, err)
 		}
 
 		// Format Go code
 		gofmtCmd := exec.Command("go", "fmt", "./...")
 		gofmtCmd.Dir = "app/service-core"
 		if output, err := gofmtCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: go fmt failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "go fmt failed: %v\nOutput: %s", err, output)
 		}
 
-		if err := config.AddIntegration("s3"); err != nil {
-			cmd.Printf("Error updating config: %v\n", err)
-			return
-		}
-		if err := formatEnabledClients(); err != nil {
-			cmd.Printf("Error formatting client after S3 add: %v\n", err)
-			return
+		if err := formatEnabledClients(con.Config); err != nil {
+			return genErr("formatting client after S3 add: %w", err)
 		}
 
 		cmd.Println("")
 		cmd.Println(config.SuccessStyle.Render("S3 integration added successfully!"))
 		cmd.Println("")
-		if cfg, err := config.ParseConfig(); err == nil && clients.HasAny(cfg) {
+		if clients.HasAny(con.Config) {
 			cmd.Println("Add this route to your client navigation:")
-			cmd.Printf("  %s\n", config.SuccessStyle.Render("/files"))
+			printRoute(cmd, "/files")
 			cmd.Println("")
 		}
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s to regenerate proto code\n", config.SuccessStyle.Render("'make gen'"))
-		cmd.Printf("  2. Run %s to regenerate SQL queries\n", config.SuccessStyle.Render("'make sql'"))
-		cmd.Printf("  3. Run %s to format generated code\n", config.SuccessStyle.Render("'make format'"))
-		cmd.Printf("  4. Run %s to apply migrations\n", config.SuccessStyle.Render("'make migrate'"))
+		runStep(cmd, 1, "make gen", "to regenerate proto code")
+		runStep(cmd, 2, "make sql", "to regenerate SQL queries")
+		runStep(cmd, 3, "make format", "to format generated code")
+		runStep(cmd, 4, "make migrate", "to apply migrations")
 		cmd.Println("  5. Add environment variables to docker-compose.yml:")
-		cmd.Println("     - S3_ACCESS_KEY_ID")
-		cmd.Println("     - S3_SECRET_ACCESS_KEY")
-		cmd.Println("     - S3_ENDPOINT")
-		cmd.Println("     - BUCKET_NAME")
+		for _, v := range integrationEnvVars["s3"] {
+			printEnvVar(cmd, v)
+		}
 		cmd.Println("  6. Add to GitHub secrets/variables:")
 		cmd.Println("     Secrets: S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY")
 		cmd.Println("     Variables: S3_ENDPOINT, BUCKET_NAME")
 		cmd.Println("")
+		return nil
 	},
 }
 
@@ -215,62 +237,74 @@ After running this command:
 3. Run 'make migrate' to create the emails table
 4. Configure Postmark environment variables in your .env file
 `,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
 		// Ensure we are inside a valid gofast project
-		if _, err := config.ParseConfig(); err != nil {
-			cmd.Printf("%v\n", err)
-			return
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+		if con.HasIntegration("postmark") {
+			return usageErr("Postmark is already added (listed in %s)", config.ConfigFileName)
+		}
+		if err := preflightMarkers(cmd, servicePackage(coreService)); err != nil {
+			return err
+		}
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		cmd.Println("")
 		cmd.Println("Adding Postmark email integration...")
 
-		if err := integrations.PostmarkAdd(email, apiKey); err != nil {
-			cmd.Printf("Error adding Postmark: %v\n", err)
-			return
+		if err := integrations.PostmarkAdd(email, apiKey, version, con.Config); err != nil {
+			return genErr("adding Postmark: %w", err)
+		}
+		// Record the integration before formatting, so a formatter failure
+		// leaves a project that knows its Postmark code and migration are in.
+		con.AddIntegration("postmark")
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %w", config.ConfigFileName, err)
 		}
 
 		// Format Go code
 		gofmtCmd := exec.Command("go", "fmt", "./...")
 		gofmtCmd.Dir = "app/service-core"
 		if output, err := gofmtCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: go fmt failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "go fmt failed: %v\nOutput: %s", err, output)
 		}
 
-		if err := config.AddIntegration("postmark"); err != nil {
-			cmd.Printf("Error updating config: %v\n", err)
-			return
-		}
-		if err := formatEnabledClients(); err != nil {
-			cmd.Printf("Error formatting client after Postmark add: %v\n", err)
-			return
+		if err := formatEnabledClients(con.Config); err != nil {
+			return genErr("formatting client after Postmark add: %w", err)
 		}
 
 		cmd.Println("")
 		cmd.Println(config.SuccessStyle.Render("Postmark integration added successfully!"))
 		cmd.Println("")
-		if cfg, err := config.ParseConfig(); err == nil && clients.HasAny(cfg) {
+		if clients.HasAny(con.Config) {
 			cmd.Println("Add this route to your client navigation:")
-			cmd.Printf("  %s\n", config.SuccessStyle.Render("/emails"))
+			printRoute(cmd, "/emails")
 			cmd.Println("")
 		}
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s to regenerate proto code\n", config.SuccessStyle.Render("'make gen'"))
-		cmd.Printf("  2. Run %s to regenerate SQL queries\n", config.SuccessStyle.Render("'make sql'"))
-		cmd.Printf("  3. Run %s to format generated code\n", config.SuccessStyle.Render("'make format'"))
-		cmd.Printf("  4. Run %s to apply migrations\n", config.SuccessStyle.Render("'make migrate'"))
+		runStep(cmd, 1, "make gen", "to regenerate proto code")
+		runStep(cmd, 2, "make sql", "to regenerate SQL queries")
+		runStep(cmd, 3, "make format", "to format generated code")
+		runStep(cmd, 4, "make migrate", "to apply migrations")
 		cmd.Println("  5. Add environment variables to docker-compose.yml:")
-		cmd.Println("     - POSTMARK_API_KEY")
-		cmd.Println("     - EMAIL_FROM")
+		for _, v := range integrationEnvVars["postmark"] {
+			printEnvVar(cmd, v)
+		}
 		cmd.Println("  6. Add to GitHub secrets/variables:")
 		cmd.Println("     Secrets: POSTMARK_API_KEY")
 		cmd.Println("     Variables: EMAIL_FROM")
 		cmd.Println("")
+		return nil
 	},
 }
@@ -1,19 +1,121 @@
 package cmd
 
 import (
+	"bufio"
+	"os"
+	"strings"
+
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authStatusCmd)
+	authCmd.AddCommand(authLogoutCmd)
+	authCmd.Flags().String("email", "", "Email to authenticate with (non-interactive, use with --api-key-stdin)")
+	authCmd.Flags().Bool("api-key-stdin", false, "Read the API key from stdin (non-interactive)")
 }
 
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Authenticate with GoFast CLI",
-	Long:  "Authenticate with GoFast CLI",
-	Run: func(cmd *cobra.Command, args []string) {
-		auth.Run()
+	Long: `Authenticate with GoFast CLI.
+
+Without flags an interactive form asks for your email and API key. In CI use:
+
+  echo "$API_KEY" | gof auth --email you@example.com --api-key-stdin
+
+or skip 'gof auth' entirely and set GOF_EMAIL and GOF_API_KEY; they take
+precedence over the saved credentials.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, _ := cmd.Flags().GetString("email")
+		keyFromStdin, _ := cmd.Flags().GetBool("api-key-stdin")
+
+		if email == "" && !keyFromStdin {
+			if !isTerminal(os.Stdin) {
+				return usageErr("stdin is not a terminal; use --email and --api-key-stdin")
+			}
+			if err := auth.Run(); err != nil {
+				return authErr("%v", err)
+			}
+			return nil
+		}
+		if email == "" || !keyFromStdin {
+			return usageErr("--email and --api-key-stdin must be used together")
+		}
+
+		apiKey, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && apiKey == "" {
+			return usageErr("reading API key from stdin: %v", err)
+		}
+		if err := auth.Login(email, strings.TrimSpace(apiKey)); err != nil {
+			return authErr("%v", err)
+		}
+		cmd.Println(config.SuccessStyle.Render("Authentication successful!"))
+		return nil
+	},
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which credentials are used and whether they are valid",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, source, err := auth.Current()
+		status := map[string]any{"source": source, "authenticated": false}
+		report.Data = status
+		if err != nil {
+			return authErr("%v", err)
+		}
+		if source == auth.SourceOffline {
+			status["authenticated"] = true
+			cmd.Println("Offline mode: credentials are not needed, templates come from a local gofast-app checkout.")
+			return nil
+		}
+		if source == auth.SourceTemplate {
+			status["authenticated"] = true
+			status["template"] = config.TemplateSource()
+			cmd.Printf("Custom template %s: credentials are not needed.\n", config.TemplateSource())
+			return nil
+		}
+		status["email"] = c.Email
+		cmd.Printf("Email:  %s\n", c.Email)
+		if source == auth.SourceEnv {
+			cmd.Printf("Source: %s and %s\n", auth.EnvEmail, auth.EnvApiKey)
+		} else {
+			status["store"] = auth.StoreName()
+			cmd.Printf("Source: saved credentials (%s)\n", auth.StoreName())
+		}
+		if err := auth.Verify(c); err != nil {
+			return authErr("credentials rejected: %v", err)
+		}
+		status["authenticated"] = true
+		cmd.Println(config.SuccessStyle.Render("Authenticated."))
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the saved credentials",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := auth.Logout()
+		if err != nil {
+			return failErr("removing credentials: %w", err)
+		}
+		if removed {
+			cmd.Println(config.SuccessStyle.Render("Logged out."))
+		} else {
+			cmd.Println("No saved credentials.")
+		}
+		if os.Getenv(auth.EnvEmail) != "" || os.Getenv(auth.EnvApiKey) != "" {
+			warnf(cmd, "%s/%s are still set and will be used", auth.EnvEmail, auth.EnvApiKey)
+		}
+		return nil
 	},
 }
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	cachePruneCmd.Flags().Int("keep", 3, "Number of most recently used templates to keep")
+}
+
+// projectTemplateVersion returns the template version the current project is
+// pinned to. Projects created before pinning are pinned to the latest
+// template on first use, so every later command agrees with it; the pin is
+// written when the calling command saves the project.
+func projectTemplateVersion(cmd *cobra.Command, email string, apiKey string, con *config.Config) (string, error) {
+	// Local and custom templates have no server version to pin.
+	if config.Offline() || config.TemplateSource() != "" {
+		return con.TemplateVersion, nil
+	}
+	if con.TemplateVersion != "" {
+		if !repo.ServerVersion(con.TemplateVersion) {
+			return "", usageErr("%s is pinned to template %s, which the GoFast server cannot serve; run with --offline or --template <source> to use the template it came from",
+				config.ConfigFileName, con.TemplateVersion)
+		}
+		return con.TemplateVersion, nil
+	}
+	t, err := repo.Fetch(email, apiKey, "")
+	if err != nil {
+		return "", downloadErr("fetching template: %w", err)
+	}
+	con.TemplateVersion = t.Version
+	warnf(cmd, "%s had no template_version; pinned it to the latest template (%s)", config.ConfigFileName, t.Version)
+	return t.Version, nil
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local template cache",
+	Long: `Manage the local template cache.
+
+Downloaded templates are cached per version under the user cache directory,
+so commands run against a project reuse the template version pinned in
+gofast.json instead of downloading it again.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached template versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := repo.CachedTemplates()
+		if err != nil {
+			return failErr("reading template cache: %w", err)
+		}
+		report.Data = entries
+		if len(entries) == 0 {
+			cmd.Println("No cached templates.")
+			return nil
+		}
+		pinned := pinnedVersion()
+		for _, e := range entries {
+			line := e.Version + "  " + e.Checksum[:min(len(e.Checksum), 12)] + "  " + humanSize(e.Size) + "  last used " + e.LastUsedAt.Format(time.DateTime)
+			if e.Version == pinned {
+				line += "  (this project)"
+			}
+			cmd.Println(line)
+		}
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old cached templates",
+	Long: `Remove cached templates except the most recently used ones.
+
+The version pinned by the project in the current directory is always kept.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetInt("keep")
+		if keep < 0 {
+			return usageErr("--keep must not be negative")
+		}
+		var pinned []string
+		if v := pinnedVersion(); v != "" {
+			pinned = append(pinned, v)
+		}
+		removed, err := repo.PruneCache(keep, pinned)
+		report.Data = removed
+		if err != nil {
+			return failErr("pruning template cache: %w", err)
+		}
+		for _, e := range removed {
+			cmd.Printf("Removed %s\n", e.Version)
+		}
+		cmd.Println(config.SuccessStyle.Render("Template cache pruned."))
+		return nil
+	},
+}
+
+// pinnedVersion is the template version of the project in the working
+// directory, if any.
+func pinnedVersion() string {
+	con, err := config.ParseConfig()
+	if err != nil {
+		return ""
+	}
+	return con.TemplateVersion
+}
+
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return strconv.FormatFloat(float64(n)/float64(div), 'f', 1, 64) + " " + string("KMGTPE"[exp]) + "iB"
+}
@@ -5,16 +5,19 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/e2e"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/htmx"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/integrations"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/next"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/ports"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/svelte"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/tanstack"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/vue"
 	"github.com/spf13/cobra"
 )
 
@@ -25,84 +28,87 @@ func init() {
 var clientCmd = &cobra.Command{
 	Use:   "client [client_type]",
 	Short: "Create a new client service",
-	Long:  "Create a new client service connected to your Go service",
+	Long:  "Create a new client service connected to your Go service (" + clients.Names() + ")",
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
-		con, err := config.ParseConfig()
+		con, err := config.Open()
 		if err != nil {
-			cmd.Printf("%v\n", err)
-			return
+			return failErr("%v", err)
 		}
+		defer func() { _ = con.Close() }()
 
 		serviceType := args[0]
 		spec, ok := clients.SpecFor(serviceType)
 		if !ok {
-			cmd.Println("Invalid service type. Valid types are: svelte, tanstack")
-			return
+			return usageErr("invalid service type %q. Valid types are: %s", serviceType, clients.Names())
 		}
 
-		if config.HasService(spec.Name) {
-			cmd.Printf("%s service already exists.\n", spec.DisplayName)
-			return
+		if con.HasService(spec.Name) {
+			return failErr("%s service already exists", spec.DisplayName)
+		}
+
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		tmpDir, err := os.MkdirTemp("", "gofast-app-*")
 		if err != nil {
-			cmd.Printf("Error creating temp directory: %v\n", err)
-			return
+			return genErr("creating temp directory: %v", err)
 		}
 		defer func() { _ = os.RemoveAll(tmpDir) }()
 
 		cwd, err := os.Getwd()
 		if err != nil {
-			cmd.Printf("Error getting working directory: %v\n", err)
-			return
-		}
-		if err := os.Chdir(tmpDir); err != nil {
-			cmd.Printf("Error changing to temp directory: %v\n", err)
-			return
+			return genErr("getting working directory: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
 
 		srcRepoName := "gofast-app-src"
-		if err := repo.DownloadRepo(email, apiKey, srcRepoName); err != nil {
-			cmd.Printf("Error downloading repository to temp directory: %v\n", err)
-			return
+		if _, err := repo.DownloadRepo(email, apiKey, version, filepath.Join(tmpDir, srcRepoName)); err != nil {
+			return downloadErr("downloading repository to temp directory: %v", err)
+		}
+
+		srcClientPath := filepath.Join(tmpDir, srcRepoName, "app", spec.ServiceDir)
+		if _, err := os.Stat(srcClientPath); err != nil {
+			return genErr("template version %s has no %s client (app/%s); run 'gof upgrade' to move to a template that has it", version, spec.DisplayName, spec.ServiceDir)
+		}
+
+		used, err := usedPorts(con.Config, cwd, spec.Name)
+		if err != nil {
+			return failErr("reading compose files: %v", err)
+		}
+		port := freePort(spec.Port, used)
+		if port != spec.Port {
+			srcRoot := filepath.Join(tmpDir, srcRepoName)
+			if _, err := ports.Rewrite(srcRoot, serviceFiles(spec.Name), spec.Port, port, portShared(con.Config, spec.Name, spec.Port, true)); err != nil {
+				return genErr("moving %s to port %s: %v", spec.DisplayName, port, err)
+			}
+			warnf(cmd, "port %s is used by %s; %s gets port %s", spec.Port, used[spec.Port], spec.DisplayName, port)
 		}
 
 		if err := copyComposeFile(tmpDir, srcRepoName, cwd, con.ProjectName, spec.ComposeFile); err != nil {
-			cmd.Printf("Error copying %s: %v\n", spec.ComposeFile, err)
-			return
+			return genErr("copying %s: %v", spec.ComposeFile, err)
 		}
 
-		srcClientPath := filepath.Join(tmpDir, srcRepoName, "app", spec.ServiceDir)
 		dstClientPath := filepath.Join(cwd, "app", spec.ServiceDir)
 
-		if _, err := os.Stat(srcClientPath); err != nil {
-			cmd.Printf("Source client folder not found in template: %v\n", err)
-			return
-		}
 		if _, err := os.Stat(dstClientPath); err == nil {
 			if err := os.RemoveAll(dstClientPath); err != nil {
-				cmd.Printf("Destination '%s' already exists and could not be removed: %v\n", dstClientPath, err)
-				return
+				return genErr("destination '%s' already exists and could not be removed: %v", dstClientPath, err)
 			}
 		}
 		if err := os.MkdirAll(filepath.Dir(dstClientPath), 0o755); err != nil {
-			cmd.Printf("Error creating destination directory: %v\n", err)
-			return
+			return genErr("creating destination directory: %v", err)
 		}
 
 		if err := os.Rename(srcClientPath, dstClientPath); err != nil {
 			if copyErr := copyDir(srcClientPath, dstClientPath); copyErr != nil {
-				cmd.Printf("Error copying client folder: %v (original move error: %v)\n", copyErr, err)
-				return
+				return genErr("copying client folder: %v (original move error: %v)", copyErr, err)
 			}
 		}
 
@@ -113,20 +119,17 @@ var clientCmd = &cobra.Command{
 
 		if !enabledIntegrations["stripe"] {
 			if err := integrations.StripeStripClient(spec.Name, dstClientPath); err != nil {
-				cmd.Printf("Error stripping stripe from client: %v\n", err)
-				return
+				return genErr("stripping stripe from client: %v", err)
 			}
 		}
 		if !enabledIntegrations["s3"] {
 			if err := integrations.S3StripClient(spec.Name, dstClientPath); err != nil {
-				cmd.Printf("Error stripping s3 from client: %v\n", err)
-				return
+				return genErr("stripping s3 from client: %v", err)
 			}
 		}
 		if !enabledIntegrations["postmark"] {
 			if err := integrations.PostmarkStripClient(spec.Name, dstClientPath); err != nil {
-				cmd.Printf("Error stripping postmark from client: %v\n", err)
-				return
+				return genErr("stripping postmark from client: %v", err)
 			}
 		}
 
@@ -134,21 +137,16 @@ var clientCmd = &cobra.Command{
 		dstE2E := filepath.Join(cwd, "e2e")
 		if _, err := os.Stat(srcE2E); err == nil {
 			if err := copyDir(srcE2E, dstE2E); err != nil {
-				cmd.Printf("Error copying e2e folder: %v\n", err)
-				return
+				return genErr("copying e2e folder: %v", err)
 			}
 		}
 
-		if err := os.Chdir(cwd); err != nil {
-			cmd.Printf("Error changing back to original directory: %v\n", err)
-			return
-		}
-
 		cmd.Println("")
 		cmd.Printf("Adding %s client service...\n", spec.DisplayName)
 
 		for _, m := range con.Models {
-			if m.Name == "skeleton" {
+			// Clients only talk to core, so models of other services get no pages.
+			if m.Name == "skeleton" || m.Service != "" {
 				continue
 			}
 
@@ -159,24 +157,21 @@ var clientCmd = &cobra.Command{
 				e2eColumns[i] = e2e.Column{Name: col.Name, Type: col.Type}
 			}
 			if err := e2e.GenerateClientE2ETest(m.Name, e2eColumns); err != nil {
-				cmd.Printf("Error generating e2e test for '%s': %v\n", m.Name, err)
-				return
+				return genErr("generating e2e test for '%s': %v", m.Name, err)
 			}
 
 			if err := generateClientScaffolding(spec.Name, m.Name, m.Columns); err != nil {
-				cmd.Printf("Error generating '%s' client pages: %v\n", m.Name, err)
-				return
+				return genErr("generating '%s' client pages: %v", m.Name, err)
 			}
 		}
 
-		if err := formatClientProject(spec.Name); err != nil {
-			cmd.Printf("Error formatting %s client: %v\n", spec.DisplayName, err)
-			return
+		if err := formatClientProject(spec.Name, "."); err != nil {
+			return genErr("formatting %s client: %v", spec.DisplayName, err)
 		}
 
-		if err := config.AddService(spec.Name, spec.Port); err != nil {
-			cmd.Printf("Error updating %s: %v\n", config.ConfigFileName, err)
-			return
+		con.AddService(spec.Name, port)
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
 		}
 
 		cmd.Println("")
@@ -185,37 +180,25 @@ var clientCmd = &cobra.Command{
 
 		var routes []string
 		for _, m := range con.Models {
-			if m.Name == "skeleton" {
+			if m.Name == "skeleton" || m.Service != "" {
 				continue
 			}
 			routes = append(routes, clientModelPath(spec.Name, m.Name))
 		}
-		if enabledIntegrations["stripe"] {
-			routes = append(routes, "/payments")
-		}
-		if enabledIntegrations["s3"] {
-			routes = append(routes, "/files")
-		}
-		if enabledIntegrations["postmark"] {
-			routes = append(routes, "/emails")
-		}
+		routes = append(routes, integrationRoutes(con.Integrations)...)
 		if len(routes) > 0 {
 			cmd.Println("Add these routes to your navigation:")
 			for _, route := range routes {
-				cmd.Printf("  %s\n", config.SuccessStyle.Render(route))
+				printRoute(cmd, route)
 			}
 			cmd.Println("")
 		}
 
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s to regenerate proto code\n", config.SuccessStyle.Render("'make gen'"))
-		switch spec.Name {
-		case clients.Svelte:
-			cmd.Printf("  2. Run %s to launch your app with the Svelte client\n", config.SuccessStyle.Render("'make starts'"))
-		case clients.Tanstack:
-			cmd.Printf("  2. Run %s to launch your app with the TanStack client\n", config.SuccessStyle.Render("'make startt'"))
-		}
+		runStep(cmd, 1, "make gen", "to regenerate proto code")
+		runStep(cmd, 2, "make "+spec.StartTarget, "to launch your app with the "+spec.DisplayName+" client")
 		cmd.Println("")
+		return nil
 	},
 }
 
@@ -233,17 +216,42 @@ func generateClientScaffolding(clientType, modelName string, columns []config.Co
 			tanstackColumns[i] = tanstack.Column{Name: col.Name, Type: col.Type}
 		}
 		return tanstack.GenerateTanstackScaffolding(modelName, tanstackColumns)
+	case clients.Next:
+		nextColumns := make([]next.Column, len(columns))
+		for i, col := range columns {
+			nextColumns[i] = next.Column{Name: col.Name, Type: col.Type}
+		}
+		return next.GenerateNextScaffolding(modelName, nextColumns)
+	case clients.Vue:
+		vueColumns := make([]vue.Column, len(columns))
+		for i, col := range columns {
+			vueColumns[i] = vue.Column{Name: col.Name, Type: col.Type}
+		}
+		return vue.GenerateVueScaffolding(modelName, vueColumns)
+	case clients.Htmx:
+		htmxColumns := make([]htmx.Column, len(columns))
+		for i, col := range columns {
+			htmxColumns[i] = htmx.Column{Name: col.Name, Type: col.Type}
+		}
+		return htmx.GenerateHtmxScaffolding(modelName, htmxColumns)
 	default:
 		return fmt.Errorf("unsupported client type %q", clientType)
 	}
 }
 
-func formatClientProject(clientType string) error {
+// formatClientProject formats the client in the project at root.
+func formatClientProject(clientType, root string) error {
 	switch clientType {
 	case clients.Svelte:
-		return svelte.FormatProject()
+		return svelte.FormatProject(root)
 	case clients.Tanstack:
-		return tanstack.FormatProject()
+		return tanstack.FormatProject(root)
+	case clients.Next:
+		return next.FormatProject(root)
+	case clients.Vue:
+		return vue.FormatProject(root)
+	case clients.Htmx:
+		return htmx.FormatProject(root)
 	default:
 		return fmt.Errorf("unsupported client type %q", clientType)
 	}
@@ -253,11 +261,32 @@ func clientModelPath(clientType, modelName string) string {
 	switch clientType {
 	case clients.Tanstack:
 		return tanstack.GetModelPath(modelName)
+	case clients.Next:
+		return next.GetModelPath(modelName)
+	case clients.Vue:
+		return vue.GetModelPath(modelName)
+	case clients.Htmx:
+		return htmx.GetModelPath(modelName)
 	default:
 		return svelte.GetModelPath(modelName)
 	}
 }
 
+// integrationRoutes returns the client routes of the enabled integrations.
+func integrationRoutes(enabled []string) []string {
+	var routes []string
+	for _, r := range []struct{ integration, route string }{
+		{"stripe", "/payments"},
+		{"s3", "/files"},
+		{"postmark", "/emails"},
+	} {
+		if contains(enabled, r.integration) {
+			routes = append(routes, r.route)
+		}
+	}
+	return routes
+}
+
 func copyComposeFile(tmpDir, srcRepoName, cwd, projectName, composeFile string) error {
 	projCompose := filepath.Join(cwd, composeFile)
 	srcCompose := filepath.Join(tmpDir, srcRepoName, composeFile)
@@ -268,7 +297,7 @@ func copyComposeFile(tmpDir, srcRepoName, cwd, projectName, composeFile string)
 	if err != nil {
 		return err
 	}
-	updated := strings.ReplaceAll(string(content), "gofast", projectName)
+	updated := renameProject(string(content), projectName)
 	return os.WriteFile(projCompose, []byte(updated), 0o644)
 }
 
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/goedit"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/integrations"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/ports"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorStatus is the outcome of a single doctor check.
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "ok"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+type doctorCheck struct {
+	Name   string       `json:"name"`
+	Status doctorStatus `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Fix    string       `json:"fix,omitempty"`
+}
+
+type doctorSection struct {
+	Name   string        `json:"name"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+type doctorReport struct {
+	OK       bool            `json:"ok"`
+	Sections []doctorSection `json:"sections"`
+}
+
+func (s *doctorSection) ok(name string) {
+	s.Checks = append(s.Checks, doctorCheck{Name: name, Status: doctorOK})
+}
+
+func (s *doctorSection) warn(name, detail, fix string) {
+	s.Checks = append(s.Checks, doctorCheck{Name: name, Status: doctorWarn, Detail: detail, Fix: fix})
+}
+
+func (s *doctorSection) fail(name, detail, fix string) {
+	s.Checks = append(s.Checks, doctorCheck{Name: name, Status: doctorFail, Detail: detail, Fix: fix})
+}
+
+// check records name as ok when cond holds and as failed otherwise.
+func (s *doctorSection) check(cond bool, name, detail, fix string) {
+	if cond {
+		s.ok(name)
+	} else {
+		s.fail(name, detail, fix)
+	}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the project against gofast.json and the local toolchain",
+	Long: `Verify that what gofast.json records is actually on disk and that the local
+toolchain is usable.
+
+For every model: proto, migration, queries, domain and transport packages,
+main.go wiring, auth flags and client routes. For every integration: domain
+and transport packages, migration and markers. For every service: its compose
+file. Then the markers the CLI relies on, and buf/sqlc/goose/docker/node/go
+versions against known-good ranges. Only a missing or unsupported go fails
+(and node when a client needs it); the other tools are warnings, so projects
+set up without Docker (init --db-url or --skip-setup) pass.
+
+Every failed check comes with a suggested fix. Use --json in CI; the command
+exits with status 1 when any check fails.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.ParseConfig()
+		if err != nil {
+			return failErr("%v", err)
+		}
+
+		result := runDoctor(cfg)
+		report.Data = result
+		printDoctorReport(cmd, result)
+		if !result.OK {
+			return failErr("doctor found failing checks")
+		}
+		return nil
+	},
+}
+
+func runDoctor(cfg *config.Config) doctorReport {
+	sections := []doctorSection{
+		doctorModels(cfg),
+		doctorIntegrations(cfg),
+		doctorServices(cfg),
+		doctorMarkers(cfg),
+		doctorToolchain(cfg),
+	}
+	report := doctorReport{OK: true, Sections: sections}
+	for _, s := range sections {
+		for _, c := range s.Checks {
+			if c.Status == doctorFail {
+				report.OK = false
+			}
+		}
+	}
+	return report
+}
+
+func printDoctorReport(cmd *cobra.Command, report doctorReport) {
+	for _, s := range report.Sections {
+		cmd.Println("")
+		cmd.Println(s.Name)
+		for _, c := range s.Checks {
+			switch c.Status {
+			case doctorOK:
+				cmd.Printf("  %s %s\n", config.SuccessStyle.Render("✓"), c.Name)
+			case doctorWarn:
+				cmd.Printf("  %s %s: %s\n", config.ActiveStyle.Render("!"), c.Name, c.Detail)
+			case doctorFail:
+				cmd.Printf("  %s %s: %s\n", config.ErrStyle.Render("✗"), c.Name, c.Detail)
+			}
+			if c.Fix != "" {
+				cmd.Printf("      fix: %s\n", c.Fix)
+			}
+		}
+	}
+	cmd.Println("")
+	if report.OK {
+		cmd.Println(config.SuccessStyle.Render("No problems found."))
+	} else {
+		cmd.Println(config.ErrStyle.Render("Some checks failed; see the suggested fixes above."))
+	}
+	cmd.Println("")
+}
+
+func doctorModels(cfg *config.Config) doctorSection {
+	s := doctorSection{Name: "Models"}
+
+	type mainFile struct {
+		file *goedit.File
+		err  error
+	}
+	mains := map[string]mainFile{}
+	authGo, authErr := os.ReadFile("app/pkg/auth/auth.go")
+	queries, queriesErr := os.ReadFile("app/service-core/storage/query.sql")
+	migrations, _ := os.ReadDir("app/service-core/storage/migrations")
+
+	for _, m := range cfg.Models {
+		name := m.Name
+		cap := capitalize(name)
+		plural := pluralizeClient.Plural(name)
+		pluralCap := capitalize(plural)
+		pkg := toGoPackageName(name)
+		service := coreService
+		if m.Service != "" {
+			service = m.Service
+		}
+		svc := servicePackage(service)
+		restore := fmt.Sprintf("restore it from git, or remove %q from gofast.json and re-run 'gof model %s ...'", name, name)
+
+		protoPath := filepath.Join(filepath.FromSlash(serviceProtoDir(service)), name+".proto")
+		s.check(fileExists(protoPath), name+": proto", protoPath+" is missing", restore)
+
+		migrationSuffix := "_create_" + plural + ".sql"
+		hasMigration := false
+		for _, e := range migrations {
+			if strings.HasSuffix(e.Name(), migrationSuffix) {
+				hasMigration = true
+				break
+			}
+		}
+		s.check(hasMigration, name+": migration", "no app/service-core/storage/migrations/*"+migrationSuffix, restore)
+
+		switch {
+		case queriesErr != nil:
+			s.fail(name+": queries", queriesErr.Error(), "restore app/service-core/storage/query.sql from git")
+		default:
+			s.check(strings.Contains(string(queries), "-- name: SelectAll"+pluralCap+" "),
+				name+": queries", "query.sql has no SelectAll"+pluralCap+" query", restore+", then run 'make sql'")
+		}
+
+		domainDir := filepath.Join("app", svc, "domain", pkg)
+		s.check(dirExists(domainDir), name+": domain package", domainDir+" is missing", restore)
+		transportDir := filepath.Join("app", svc, "transport", pkg)
+		s.check(dirExists(transportDir), name+": transport package", transportDir+" is missing", restore)
+
+		mainPath := "app/" + svc + "/main.go"
+		main, parsed := mains[svc]
+		if !parsed {
+			main.file, main.err = goedit.ParseFile(mainPath)
+			mains[svc] = main
+		}
+		mainGo := main.file
+		switch {
+		case main.err != nil:
+			s.fail(name+": main.go wiring", main.err.Error(), "fix "+mainPath+" so it parses")
+		case !mainGo.HasImport(cfg.GoModule()+"/"+svc+"/domain/"+pkg) || !mainGo.HasImport(cfg.GoModule()+"/"+svc+"/transport/"+pkg):
+			s.fail(name+": main.go wiring", "main.go does not import the "+pkg+" domain and transport packages",
+				"add the imports back, or remove the wiring and let 'gof model' re-add it")
+		case !strings.Contains(string(mainGo.Source()), "New"+cap+"ServiceHandler("):
+			s.fail(name+": main.go wiring", "main.go does not mount New"+cap+"ServiceHandler",
+				"add the server.Mount(...) lines for "+name+" before GF_MAIN_MOUNT_ROUTES_END")
+		default:
+			s.ok(name + ": main.go wiring")
+		}
+
+		switch {
+		case authErr != nil:
+			s.fail(name+": auth flags", authErr.Error(), "restore app/pkg/auth/auth.go from git")
+		case !strings.Contains(string(authGo), "Get"+pluralCap) || !strings.Contains(string(authGo), "Create"+cap):
+			s.fail(name+": auth flags", "auth.go has no Get"+pluralCap+"/Create"+cap+" flags",
+				"add Get"+pluralCap+", Create"+cap+", Edit"+cap+", Remove"+cap+" before GF_ACCESS_FLAGS_END and to UserAccess")
+		default:
+			s.ok(name + ": auth flags")
+		}
+
+		if m.Service != "" {
+			// Clients only get pages for core's models.
+			continue
+		}
+		for _, client := range clients.Enabled(cfg) {
+			route := filepath.Join("app", client.ServiceDir, filepath.FromSlash(client.ModelsRouteSubpath), plural)
+			s.check(dirExists(route), name+": "+client.DisplayName+" routes", route+" is missing", restore)
+		}
+	}
+	return s
+}
+
+func doctorIntegrations(cfg *config.Config) doctorSection {
+	s := doctorSection{Name: "Integrations"}
+	mainGo, mainErr := os.ReadFile("app/service-core/main.go")
+	migrations, _ := os.ReadDir("app/service-core/storage/migrations")
+
+	for _, name := range cfg.Integrations {
+		pkg, known := integrations.Domains[name]
+		if !known {
+			s.fail(name, "unknown integration in gofast.json", "remove it from the integrations list")
+			continue
+		}
+		readd := fmt.Sprintf("remove %q from gofast.json and re-run 'gof add %s'", name, name)
+
+		domainDir := filepath.Join("app", "service-core", "domain", pkg)
+		s.check(dirExists(domainDir), name+": domain package", domainDir+" is missing", readd)
+		transportDir := filepath.Join("app", "service-core", "transport", pkg)
+		s.check(dirExists(transportDir), name+": transport package", transportDir+" is missing", readd)
+
+		hasMigration := false
+		for _, e := range migrations {
+			if strings.HasSuffix(e.Name(), "_"+integrations.Migrations[name]) {
+				hasMigration = true
+				break
+			}
+		}
+		s.check(hasMigration, name+": migration", "no *_"+integrations.Migrations[name]+" migration", readd)
+
+		marker := integrations.Names[name]
+		switch {
+		case mainErr != nil:
+			s.fail(name+": markers", mainErr.Error(), "restore app/service-core/main.go from git")
+		default:
+			regions, err := markers.Regions(string(mainGo), []markers.Style{markers.Slash}, marker)
+			switch {
+			case err != nil:
+				s.fail(name+": markers", "main.go: "+err.Error(), "run 'gof markers check'")
+			case len(regions) == 0:
+				s.fail(name+": markers", "main.go has no GF_"+marker+" block", readd)
+			default:
+				s.ok(name + ": markers")
+			}
+		}
+
+		for _, client := range clients.Enabled(cfg) {
+			sub, err := integrations.RouteSubpath(client, name)
+			if err != nil {
+				continue
+			}
+			route := filepath.Join("app", client.ServiceDir, filepath.FromSlash(sub))
+			s.check(fileExists(route) || dirExists(route), name+": "+client.DisplayName+" route", route+" is missing", readd)
+		}
+	}
+	if len(cfg.Integrations) == 0 {
+		s.ok("no integrations enabled")
+	}
+	return s
+}
+
+func doctorServices(cfg *config.Config) doctorSection {
+	s := doctorSection{Name: "Services"}
+	bindings, bindingsErr := ports.Bindings(".")
+	owners := map[string]string{}
+	for _, svc := range cfg.Services {
+		compose := serviceFiles(svc.Name).Compose
+		s.check(fileExists(compose), svc.Name+": compose file", compose+" is missing",
+			"restore "+compose+" from git, or remove the service from gofast.json and add it again")
+
+		if other, ok := owners[svc.Port]; ok {
+			used, _ := usedPorts(cfg, ".", svc.Name)
+			s.fail(svc.Name+": port", other+" and "+svc.Name+" both use port "+svc.Port,
+				"run 'gof service port "+svc.Name+" "+freePort(svc.Port, used)+"'")
+			continue
+		}
+		owners[svc.Port] = svc.Name
+		if bindingsErr != nil || !fileExists(compose) {
+			continue
+		}
+		published := false
+		for _, b := range bindings {
+			if b.File == compose && b.Port == svc.Port {
+				published = true
+			}
+		}
+		if published {
+			s.ok(svc.Name + ": port " + svc.Port)
+		} else {
+			s.warn(svc.Name+": port", compose+" does not publish port "+svc.Port+" recorded in gofast.json",
+				"run 'gof service port "+svc.Name+" <port>' to move it, or fix the ports entry in "+compose)
+		}
+	}
+	if bindingsErr != nil {
+		s.fail("compose ports", bindingsErr.Error(), "fix the docker-compose*.yml files so they can be read")
+	}
+	byPort := map[string][]string{}
+	var order []string
+	for _, b := range bindings {
+		if len(byPort[b.Port]) == 0 {
+			order = append(order, b.Port)
+		}
+		byPort[b.Port] = append(byPort[b.Port], fmt.Sprintf("%s:%d", b.File, b.Line))
+	}
+	for _, port := range order {
+		if where := byPort[port]; len(where) > 1 {
+			s.fail("port "+port, "published more than once: "+strings.Join(where, ", "),
+				"move one of the services with 'gof service port <name> <port>'")
+		}
+	}
+	if cfg.InfraPopulated {
+		s.check(dirExists("infra"), "infra", "infra_populated is set but infra/ is missing", "run 'gof infra' again")
+	}
+	if cfg.MonitoringPopulated {
+		s.check(fileExists("docker-compose.monitoring.yml"), "monitoring", "monitoring_populated is set but docker-compose.monitoring.yml is missing", "run 'gof mon' again")
+	}
+	return s
+}
+
+func doctorMarkers(cfg *config.Config) doctorSection {
+	s := doctorSection{Name: "Markers"}
+	problems, err := markers.Check(".", projectMarkerRules(cfg))
+	if err != nil {
+		s.fail("markers", err.Error(), "")
+		return s
+	}
+	for _, p := range problems {
+		fix := "fix the marker by hand"
+		if p.Fixable {
+			fix = "run 'gof markers check --fix'"
+		}
+		loc := p.Path
+		if p.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", p.Path, p.Line)
+		}
+		detail := p.Token + ": " + p.Msg
+		if p.Token == "-" {
+			detail = p.Msg
+		}
+		if p.Severity == markers.Failure {
+			s.fail(loc, detail, fix)
+		} else {
+			s.warn(loc, detail, fix)
+		}
+	}
+	if len(problems) == 0 {
+		s.ok("all markers in place")
+	}
+	return s
+}
+
+// toolRange is a known-good version range: at least Min, below Below (when set).
+// Only Required tools fail the check; the others are warnings naming what
+// they are NeededFor, since projects set up with --db-url or --skip-setup
+// (CI, no Docker) work without them.
+type toolRange struct {
+	Name      string
+	Args      []string
+	Min       string
+	Below     string
+	Install   string
+	Required  bool
+	NeededFor string
+}
+
+var doctorTools = []toolRange{
+	{Name: "go", Args: []string{"version"}, Min: "1.23", Below: "2", Install: "https://go.dev/doc/install", Required: true},
+	{Name: "buf", Args: []string{"--version"}, Min: "1.28", Below: "2", Install: "https://buf.build/docs/cli/installation/", NeededFor: "'make gen'"},
+	{Name: "sqlc", Args: []string{"version"}, Min: "1.25", Below: "2", Install: "https://docs.sqlc.dev/en/latest/overview/install.html", NeededFor: "'make sql'"},
+	{Name: "goose", Args: []string{"--version"}, Min: "3.18", Below: "4", Install: "https://github.com/pressly/goose#install", NeededFor: "migrations"},
+	{Name: "docker", Args: []string{"version", "--format", "{{.Client.Version}}"}, Min: "24", Install: "https://docs.docker.com/engine/install/", NeededFor: "running the project locally"},
+	{Name: "docker compose", Args: []string{"compose", "version", "--short"}, Min: "2.20", Below: "3", Install: "https://docs.docker.com/compose/install/", NeededFor: "running the project locally"},
+	{Name: "node", Args: []string{"--version"}, Min: "20", Install: "https://nodejs.org/en/download", NeededFor: "the clients"},
+}
+
+var versionRe = regexp.MustCompile(`\d+(\.\d+)+|\d+`)
+
+func doctorToolchain(cfg *config.Config) doctorSection {
+	s := doctorSection{Name: "Toolchain"}
+	for _, tool := range doctorTools {
+		required := tool.Required || (tool.Name == "node" && clients.NeedsNode(cfg))
+		bin := strings.Fields(tool.Name)[0]
+		name := fmt.Sprintf("%s (>= %s", tool.Name, tool.Min)
+		if tool.Below != "" {
+			name += ", < " + tool.Below
+		}
+		name += ")"
+
+		report := s.warn
+		if required {
+			report = s.fail
+		}
+
+		if _, err := exec.LookPath(bin); err != nil {
+			detail := "not found in PATH"
+			if !required && tool.NeededFor != "" {
+				detail += "; needed for " + tool.NeededFor
+			}
+			report(name, detail, "install it: "+tool.Install)
+			continue
+		}
+		out, err := exec.Command(bin, tool.Args...).CombinedOutput()
+		if err != nil {
+			report(name, fmt.Sprintf("'%s %s' failed: %v", bin, strings.Join(tool.Args, " "), err), "install it: "+tool.Install)
+			continue
+		}
+		// "go version" prints "go version go1.25.0 linux/amd64"; drop the prefix so
+		// the first number found is the Go version.
+		version := versionRe.FindString(strings.TrimPrefix(strings.TrimSpace(string(out)), "go version go"))
+		if version == "" {
+			s.warn(name, "could not read version from "+strings.TrimSpace(string(out)), "")
+			continue
+		}
+		if compareVersions(version, tool.Min) < 0 || (tool.Below != "" && compareVersions(version, tool.Below) >= 0) {
+			report(name, "found "+version, "install a supported version: "+tool.Install)
+			continue
+		}
+		s.Checks = append(s.Checks, doctorCheck{Name: name, Status: doctorOK, Detail: version})
+	}
+	return s
+}
+
+// compareVersions compares dotted numeric versions; missing parts count as 0.
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
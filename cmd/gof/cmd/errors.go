@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
+)
+
+// Exit codes gof terminates with. Scripts and CI can branch on them instead
+// of parsing output.
+const (
+	ExitFailure  = 1 // anything not covered below
+	ExitUsage    = 2 // bad arguments, flags or model definitions
+	ExitAuth     = 3 // missing or rejected credentials
+	ExitDeps     = 4 // required tools not installed
+	ExitDownload = 5 // template could not be downloaded
+	ExitGenerate = 6 // code generation, formatting or setup failed
+)
+
+// exitError carries the exit code a command wants gof to terminate with.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func withCode(code int, format string, args ...any) error {
+	return &exitError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+func usageErr(format string, args ...any) error {
+	return withCode(ExitUsage, format, args...)
+}
+
+func authErr(format string, args ...any) error {
+	return withCode(ExitAuth, format, args...)
+}
+
+func depsErr(format string, args ...any) error {
+	return withCode(ExitDeps, format, args...)
+}
+
+func downloadErr(format string, args ...any) error {
+	return withCode(ExitDownload, format, args...)
+}
+
+func genErr(format string, args ...any) error {
+	return withCode(ExitGenerate, format, args...)
+}
+
+func failErr(format string, args ...any) error {
+	return withCode(ExitFailure, format, args...)
+}
+
+// exitCode maps an error returned from a command to the process exit code.
+// A template download failure wins over the code of whatever step wrapped it.
+// Errors that never went through a RunE come from cobra itself (unknown
+// command, bad flag, wrong argument count) and count as usage errors.
+func exitCode(err error) int {
+	var d *repo.DownloadError
+	if errors.As(err, &d) {
+		return ExitDownload
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return ExitUsage
+}
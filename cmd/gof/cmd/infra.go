@@ -18,46 +18,41 @@ var infraCmd = &cobra.Command{
 	Use:   "infra",
 	Short: "Add infrastructure files (monitoring compose and infra folder)",
 	Args:  cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
-		con, err := config.ParseConfig()
+		con, err := config.Open()
 		if err != nil {
-			cmd.Printf("%v\n", err)
-			return
+			return failErr("%v", err)
 		}
+		defer func() { _ = con.Close() }()
 		if con.InfraPopulated {
 			cmd.Println("Infrastructure files have already been added to this project.")
-			return
+			return nil
+		}
+
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		tmpDir, err := os.MkdirTemp("", "gofast-infra-*")
 		if err != nil {
-			cmd.Printf("Error creating temp directory: %v\n", err)
-			return
+			return genErr("creating temp directory: %v", err)
 		}
 		defer func() { _ = os.RemoveAll(tmpDir) }()
 
 		cwd, err := os.Getwd()
 		if err != nil {
-			cmd.Printf("Error getting working directory: %v\n", err)
-			return
-		}
-
-		if err := os.Chdir(tmpDir); err != nil {
-			cmd.Printf("Error changing to temp directory: %v\n", err)
-			return
+			return genErr("getting working directory: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
 
 		srcRepoName := "gofast-app-src"
-		if err := repo.DownloadRepo(email, apiKey, srcRepoName); err != nil {
-			cmd.Printf("Error downloading repository to temp directory: %v\n", err)
-			return
+		if _, err := repo.DownloadRepo(email, apiKey, version, filepath.Join(tmpDir, srcRepoName)); err != nil {
+			return downloadErr("downloading repository to temp directory: %v", err)
 		}
 
 		srcRoot := filepath.Join(tmpDir, srcRepoName)
@@ -67,8 +62,7 @@ var infraCmd = &cobra.Command{
 		if _, err := os.Stat(dstInfraDir); err == nil {
 			cmd.Printf("Directory '%s' already exists. Skipping copy.\n", dstInfraDir)
 		} else if err := copyDir(srcInfraDir, dstInfraDir); err != nil {
-			cmd.Printf("Error copying infra directory: %v\n", err)
-			return
+			return genErr("copying infra directory: %v", err)
 		}
 
 		// If monitoring hasn't been added yet, remove monitoring.tf from infra
@@ -76,7 +70,7 @@ var infraCmd = &cobra.Command{
 		if !con.MonitoringPopulated {
 			monitoringTf := filepath.Join(dstInfraDir, "monitoring.tf")
 			if err := os.Remove(monitoringTf); err != nil && !os.IsNotExist(err) {
-				cmd.Printf("Warning: could not remove monitoring.tf: %v\n", err)
+				warnf(cmd, "could not remove monitoring.tf: %v", err)
 			}
 		}
 
@@ -86,20 +80,12 @@ var infraCmd = &cobra.Command{
 		if _, err := os.Stat(dstGithubDir); err == nil {
 			cmd.Printf("Directory '%s' already exists. Skipping copy.\n", dstGithubDir)
 		} else if err := copyDir(srcGithubDir, dstGithubDir); err != nil {
-			cmd.Printf("Error copying .github directory: %v\n", err)
-			return
+			return genErr("copying .github directory: %v", err)
 		}
 
-		err = os.Chdir(cwd)
-		if err != nil {
-			cmd.Printf("Error returning to project directory: %v\n", err)
-			return
-		}
-
-		err = config.MarkInfraPopulated()
-		if err != nil {
-			cmd.Printf("Error updating gofast config: %v\n", err)
-			return
+		con.InfraPopulated = true
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
 		}
 
 		cmd.Println("")
@@ -108,15 +94,17 @@ var infraCmd = &cobra.Command{
 		cmd.Println(config.SuccessStyle.Render("Infrastructure files added successfully!"))
 		cmd.Println("")
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s\n", config.SuccessStyle.Render("'cd infra && cp .env.example .env'"))
+		runStep(cmd, 1, "cd infra && cp .env.example .env", "")
 		cmd.Println("  2. Update infra/.env with your server details")
 		cmd.Println("  3. Review and run the setup scripts (setup_rke2.sh, setup_app.sh)")
 		cmd.Println("")
 		cmd.Printf("See %s for the full workflow.\n", config.SuccessStyle.Render("'infra/README.md'"))
 		cmd.Println("")
 		if !con.MonitoringPopulated {
+			report.NextSteps = append(report.NextSteps, "gof mon")
 			cmd.Printf("Run %s to add local development monitoring stack.\n", config.SuccessStyle.Render("'gof mon'"))
 			cmd.Println("")
 		}
+		return nil
 	},
 }
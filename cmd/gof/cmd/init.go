@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/fs"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/gomod"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/integrations"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
 	"github.com/spf13/cobra"
@@ -16,19 +22,53 @@ import (
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().StringSlice("client", nil, "Clients to include ("+clients.Names()+")")
+	initCmd.Flags().StringSlice("with", nil, "Integrations to include (stripe, s3, postmark)")
+	initCmd.Flags().Bool("infra", false, "Include the infrastructure files (same as 'gof infra')")
+	initCmd.Flags().Bool("mon", false, "Include the monitoring stack (same as 'gof mon')")
+	initCmd.Flags().String("module", "", "Go module path of the project (default \"gofast\")")
+	initCmd.Flags().String("db-url", "", "Apply the migrations to this PostgreSQL database instead of a Docker container")
+	initCmd.Flags().Bool("skip-setup", false, "Only generate the project files; print the setup commands to run afterwards")
+	initCmd.Flags().Bool("keep-on-failure", false, "Keep the half-built project (and stop, not remove, its containers) when a step fails")
 }
 
 var initCmd = &cobra.Command{
 	Use:   "init [project_name]",
 	Short: "Initialize the Go service",
-	Long:  "Initialize the Go service with Docker and PostgreSQL setup",
-	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		dependencies := map[string]string{
-			"buf":    "https://buf.build/docs/cli/installation/",
-			"sqlc":   "https://docs.sqlc.dev/en/latest/overview/install.html",
-			"goose":  "https://github.com/pressly/goose#install",
-			"docker": "https://docs.docker.com/engine/install/",
+	Long: `Initialize the Go service with Docker and PostgreSQL setup.
+
+Clients, integrations, infrastructure and monitoring can be included right
+away instead of adding them one by one afterwards:
+
+  gof init myapp --client svelte --with stripe,s3,postmark --infra --mon
+
+The project is generated from a single template download, with the selected
+integrations kept rather than stripped and added back.
+
+By default the migrations are applied to a PostgreSQL container started with
+Docker. Use --db-url to apply them to an existing database instead, or
+--skip-setup to only generate the files. When buf or sqlc is missing, code
+generation is skipped and the commands to run are printed.
+
+The project is built in a staging directory and moved into place only when
+every step succeeded; a failed init removes it and the containers it
+started. Use --keep-on-failure to keep them for debugging.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		skipSetup, _ := cmd.Flags().GetBool("skip-setup")
+		dbURL, _ := cmd.Flags().GetString("db-url")
+		if skipSetup && dbURL != "" {
+			return usageErr("--db-url and --skip-setup cannot be used together")
+		}
+
+		// buf and sqlc are not required: their steps are skipped and printed
+		// as next steps instead. Docker only runs the local database.
+		dependencies := map[string]string{}
+		if !skipSetup {
+			dependencies["goose"] = "https://github.com/pressly/goose#install"
+			if dbURL == "" {
+				dependencies["docker"] = "https://docs.docker.com/engine/install/"
+			}
 		}
 
 		var missingDeps []string
@@ -40,7 +80,7 @@ var initCmd = &cobra.Command{
 		}
 
 		// Check for docker-compose
-		if _, err := exec.LookPath("docker"); err == nil {
+		if _, err := exec.LookPath("docker"); err == nil && dependencies["docker"] != "" {
 			if err := exec.Command("docker", "compose", "version").Run(); err != nil {
 				missingDeps = append(missingDeps, "docker compose")
 				dependencies["docker compose"] = "https://docs.docker.com/compose/install/"
@@ -52,155 +92,437 @@ var initCmd = &cobra.Command{
 			for _, dep := range missingDeps {
 				cmd.Printf("  - %s: %s\n", dep, dependencies[dep])
 			}
-			return
+			return depsErr("missing dependencies: %s", strings.Join(missingDeps, ", "))
 		}
 
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 		projectName := args[0]
 		if projectName == "" {
-			cmd.Println("Project name cannot be empty.")
-			return
+			return usageErr("project name cannot be empty")
 		}
 		// check if the project directory already exists
 		_, err = os.Stat(projectName)
 		if err == nil {
-			cmd.Printf("Project directory '%s' already exists. Please choose a different name.\n", projectName)
-			return
+			return usageErr("project directory '%s' already exists. Please choose a different name", projectName)
 		}
-		// download the repository
-		err = repo.DownloadRepo(email, apiKey, projectName)
+		cfg, err := initFeatures(cmd, projectName)
 		if err != nil {
-			cmd.Printf("Error downloading repository: %v\n", err)
-			return
+			return err
 		}
-		if err := os.RemoveAll(filepath.Join(projectName, ".git")); err != nil {
-			cmd.Printf("Warning: could not remove template git metadata: %v\n", err)
+		keepOnFailure, _ := cmd.Flags().GetBool("keep-on-failure")
+
+		// The project is built in a staging directory next to its final place
+		// and moved there only once every step succeeded, so a failed init
+		// leaves nothing behind and can simply be run again. The build
+		// directory has the project's name, so Docker Compose names the
+		// containers and volumes as it will after the move.
+		stage, err := os.MkdirTemp(filepath.Dir(projectName), ".gof-init-*")
+		if err != nil {
+			return genErr("creating staging directory: %v", err)
 		}
-		// remove template-only folders and files
-		for _, client := range clients.All() {
-			if err := os.RemoveAll(filepath.Join(projectName, "app", client.ServiceDir)); err != nil {
-				cmd.Printf("Warning: could not remove initial %s client folder: %v\n", client.DisplayName, err)
+		dir := filepath.Join(stage, filepath.Base(projectName))
+		done, dbStarted := false, false
+		defer func() {
+			if !done {
+				abortInit(cmd, dir, projectName, dbStarted, keepOnFailure)
 			}
+			_ = os.RemoveAll(stage)
+		}()
+		// Ctrl-C stops the running step and still cleans up.
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		// download the repository
+		version, err := repo.DownloadRepo(email, apiKey, "", dir)
+		if err != nil {
+			return downloadErr("downloading repository: %v", err)
 		}
-		if err := os.RemoveAll(filepath.Join(projectName, "monitoring")); err != nil {
-			cmd.Printf("Warning: could not remove monitoring folder: %v\n", err)
-		}
-		if err := os.RemoveAll(filepath.Join(projectName, "infra")); err != nil {
-			cmd.Printf("Warning: could not remove infra folder: %v\n", err)
-		}
-		if err := os.Remove(filepath.Join(projectName, "docker-compose.monitoring.yml")); err != nil && !os.IsNotExist(err) {
-			cmd.Printf("Warning: could not remove monitoring docker compose file: %v\n", err)
-		}
-		for _, client := range clients.All() {
-			if err := os.Remove(filepath.Join(projectName, client.ComposeFile)); err != nil && !os.IsNotExist(err) {
-				cmd.Printf("Warning: could not remove %s docker compose file: %v\n", client.DisplayName, err)
+		defer recordCreatedTree(projectName)
+		for _, client := range clients.Enabled(cfg) {
+			if _, err := os.Stat(filepath.Join(dir, "app", client.ServiceDir)); err != nil {
+				return genErr("the template has no %s client (app/%s)", client.DisplayName, client.ServiceDir)
 			}
 		}
-		if err := os.RemoveAll(filepath.Join(projectName, "e2e")); err != nil {
-			cmd.Printf("Warning: could not remove e2e folder: %v\n", err)
-		}
-		if err := os.RemoveAll(filepath.Join(projectName, ".github")); err != nil {
-			cmd.Printf("Warning: could not remove .github folder: %v\n", err)
-		}
-		// Strip optional integrations - user can add them back with 'gof add <integration>'
-		if err := integrations.StripeStrip(projectName); err != nil {
-			cmd.Printf("Error stripping stripe: %v\n", err)
-			return
-		}
-		if err := integrations.S3Strip(projectName); err != nil {
-			cmd.Printf("Error stripping s3: %v\n", err)
-			return
-		}
-		if err := integrations.PostmarkStrip(projectName); err != nil {
-			cmd.Printf("Error stripping postmark: %v\n", err)
-			return
-		}
-		dcPath := filepath.Join(projectName, "docker-compose.yml")
-		dcContent, err := os.ReadFile(dcPath)
-		if err != nil {
-			cmd.Printf("Error reading %s: %v\n", dcPath, err)
-			return
-		}
-		newDcContent := strings.ReplaceAll(string(dcContent), "gofast", projectName)
-		if err := os.WriteFile(dcPath, []byte(newDcContent), 0644); err != nil {
-			cmd.Printf("Error writing to %s: %v\n", dcPath, err)
-			return
+		// remove the clients, infra and monitoring not asked for and strip the
+		// other integrations - they can be added later with 'gof client',
+		// 'gof add', 'gof infra' and 'gof mon'
+		if err := renderTemplate(dir, cfg); err != nil {
+			return genErr("preparing project: %v", err)
 		}
 
 		// create gofast.json config using the config package
-		if err := config.Initialize(projectName); err != nil {
-			cmd.Printf("Error creating gofast.json file: %v\n", err)
-			return
+		// Only server templates are pinned; a local checkout or custom
+		// template has no version the server can serve later.
+		if repo.ServerVersion(version) && config.TemplateSource() == "" {
+			cfg.TemplateVersion = version
+		}
+		if err := config.Initialize(dir, cfg); err != nil {
+			return genErr("creating gofast.json file: %v", err)
 		}
 
 		// run scripts to set up the project
 		cmd.Println("")
 		cmd.Printf("Initializing project '%s'...\n", projectName)
-		scripts := []string{
-			"make keys",
-			"make sql",
-			"make gen",
-			"docker compose up postgres -d --wait",
-			"make migrate",
-			"docker compose stop",
-		}
-		messages := []string{
-			"Generating Public/Private keys...",
-			"Generating SQL queries...",
-			"Generating proto code...",
-			"Starting PostgreSQL container...",
-			"Applying database migrations...",
-			"Stopping PostgreSQL container...",
-		}
-		for i, script := range scripts {
-			cmd.Printf("%s\n", messages[i])
-			parts := strings.Fields(script)
-			cmdExec := exec.Command(parts[0], parts[1:]...)
-			cmdExec.Dir = projectName
+		var pending []string
+		for _, step := range initSteps(dbURL) {
+			if skipSetup {
+				if !step.teardown {
+					pending = append(pending, step.command)
+				}
+				continue
+			}
+			if step.needs != "" {
+				if _, err := exec.LookPath(step.needs); err != nil {
+					warnf(cmd, "%s not found, skipping '%s'", step.needs, step.command)
+					pending = append(pending, step.command)
+					continue
+				}
+			}
+			cmd.Printf("%s\n", step.message)
+			args := step.args
+			if args == nil {
+				args = strings.Fields(step.command)
+			}
+			dbStarted = dbStarted || step.startsDB
+			cmdExec := exec.CommandContext(ctx, args[0], args[1:]...)
+			cmdExec.Dir = dir
 			output, err := cmdExec.CombinedOutput()
+			if ctx.Err() != nil {
+				return failErr("interrupted while running '%s'", step.command)
+			}
 			if err != nil {
-				cmd.Printf("Error running '%s': %v\nOutput: %s\n", script, err, output)
-				return
+				return genErr("running '%s': %v\nOutput: %s", step.command, err, output)
 			}
 		}
 
 		// Format Go code
 		gofmtCmd := exec.Command("go", "fmt", "./...")
-		gofmtCmd.Dir = filepath.Join(projectName, "app", "service-core")
+		gofmtCmd.Dir = filepath.Join(dir, "app", "service-core")
 		if output, err := gofmtCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: go fmt failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "go fmt failed: %v\nOutput: %s", err, output)
+		}
+
+		for _, client := range clients.Enabled(cfg) {
+			if skipSetup && client.Node {
+				pending = append(pending, "npm ci --prefix app/"+client.ServiceDir)
+				continue
+			}
+			cmd.Printf("Formatting %s client...\n", client.DisplayName)
+			if err := formatClientProject(client.Name, dir); err != nil {
+				return genErr("formatting %s client: %v", client.DisplayName, err)
+			}
 		}
 
 		// Initialize git repo with initial commit
 		gitInitCmd := exec.Command("git", "init")
-		gitInitCmd.Dir = projectName
+		gitInitCmd.Dir = dir
 		if output, err := gitInitCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: git init failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "git init failed: %v\nOutput: %s", err, output)
 		}
 		gitAddCmd := exec.Command("git", "add", ".")
-		gitAddCmd.Dir = projectName
+		gitAddCmd.Dir = dir
 		if output, err := gitAddCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: git add failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "git add failed: %v\nOutput: %s", err, output)
 		}
 		gitCommitCmd := exec.Command("git", "commit", "-m", "Initial commit")
-		gitCommitCmd.Dir = projectName
+		gitCommitCmd.Dir = dir
 		if output, err := gitCommitCmd.CombinedOutput(); err != nil {
-			cmd.Printf("Warning: git commit failed: %v\nOutput: %s\n", err, output)
+			warnf(cmd, "git commit failed: %v\nOutput: %s", err, output)
 		}
 
+		if ctx.Err() != nil {
+			return failErr("interrupted")
+		}
+		if err := os.Rename(dir, projectName); err != nil {
+			return genErr("moving project into place: %v", err)
+		}
+		done = true
+
 		cmd.Println("")
 		cmd.Println(config.SuccessStyle.Render("Project '" + projectName + "' initialized successfully!"))
 		cmd.Println("")
+		if routes := integrationRoutes(cfg.Integrations); len(routes) > 0 && clients.HasAny(cfg) {
+			cmd.Println("Add these routes to your client navigation:")
+			for _, route := range routes {
+				printRoute(cmd, route)
+			}
+			cmd.Println("")
+		}
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s\n", config.SuccessStyle.Render("'cd "+projectName+"'"))
-		cmd.Printf("  2. Run %s to start the server\n", config.SuccessStyle.Render("'make start'"))
+		step := 1
+		runStep(cmd, step, "cd "+projectName, "")
+		step++
+		for _, command := range pending {
+			runStep(cmd, step, command, "")
+			step++
+		}
+		if len(cfg.Integrations) > 0 {
+			cmd.Printf("  %d. Add environment variables to docker-compose.yml:\n", step)
+			for _, name := range cfg.Integrations {
+				for _, v := range integrationEnvVars[name] {
+					printEnvVar(cmd, v)
+				}
+			}
+			step++
+		}
+		if cfg.InfraPopulated {
+			runStep(cmd, step, "cp infra/.env.example infra/.env", "and update it with your server details (see infra/README.md)")
+			step++
+		}
+		switch {
+		case cfg.MonitoringPopulated:
+			runStep(cmd, step, "make startm", "to start the server with the local monitoring stack")
+		case clients.HasAny(cfg):
+			spec := clients.Enabled(cfg)[0]
+			runStep(cmd, step, "make "+spec.StartTarget, "to start the server with the "+spec.DisplayName+" client")
+		default:
+			runStep(cmd, step, "make start", "to start the server")
+		}
 		cmd.Println("")
 		cmd.Println("To create a GitHub repo:")
 		cmd.Printf("  %s\n", config.SuccessStyle.Render("gh repo create "+projectName+" --private --source="+projectName+" --push"))
 		cmd.Println("")
+		return nil
 	},
 }
+
+// abortInit cleans up after a failed init: the containers it started are
+// removed, or only stopped when keep is set, in which case the half-built
+// project is moved to projectName for debugging instead of being deleted
+// with the staging directory.
+func abortInit(cmd *cobra.Command, dir, projectName string, dbStarted, keep bool) {
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	if dbStarted {
+		args := []string{"compose", "down"}
+		if keep {
+			args = []string{"compose", "stop"}
+		}
+		// Not tied to the interrupted context: this must run after Ctrl-C.
+		down := exec.Command("docker", args...)
+		down.Dir = dir
+		if output, err := down.CombinedOutput(); err != nil {
+			warnf(cmd, "could not clean up the PostgreSQL container: %v\nOutput: %s", err, output)
+		}
+	}
+	if !keep {
+		return
+	}
+	if err := os.Rename(dir, projectName); err != nil {
+		warnf(cmd, "could not keep the project files: %v", err)
+		return
+	}
+	warnf(cmd, "kept the half-built project in '%s' for debugging; remove it before running 'gof init' again", projectName)
+}
+
+// initStep is a command run in the new project to set it up.
+type initStep struct {
+	message  string
+	command  string   // shown to the user
+	args     []string // run instead of the command's fields when set
+	needs    string   // the step is skipped when this tool is missing
+	teardown bool     // not worth running by hand when setup is skipped
+	startsDB bool     // starts containers that are torn down when init fails
+}
+
+// initSteps returns the setup commands: key and code generation, then the
+// migrations, applied to dbURL when set and to a PostgreSQL container
+// otherwise.
+func initSteps(dbURL string) []initStep {
+	steps := []initStep{
+		{message: "Generating Public/Private keys...", command: "make keys"},
+		{message: "Generating SQL queries...", command: "make sql", needs: "sqlc"},
+		{message: "Generating proto code...", command: "make gen", needs: "buf"},
+	}
+	if dbURL != "" {
+		// The URL is left out of the shown command; it may hold a password.
+		return append(steps, initStep{
+			message: "Applying database migrations...",
+			command: "goose -dir app/service-core/storage/migrations postgres <db-url> up",
+			args:    []string{"goose", "-dir", "app/service-core/storage/migrations", "postgres", dbURL, "up"},
+		})
+	}
+	return append(steps,
+		initStep{message: "Starting PostgreSQL container...", command: "docker compose up postgres -d --wait", startsDB: true},
+		initStep{message: "Applying database migrations...", command: "make migrate"},
+		initStep{message: "Stopping PostgreSQL container...", command: "docker compose stop", teardown: true},
+	)
+}
+
+// initFeatures validates the --client, --with, --module, --infra and --mon
+// flags and returns the config of the project they describe.
+func initFeatures(cmd *cobra.Command, projectName string) (*config.Config, error) {
+	cfg := config.New(projectName, "")
+	clientNames, _ := cmd.Flags().GetStringSlice("client")
+	for _, name := range clientNames {
+		spec, ok := clients.SpecFor(name)
+		if !ok {
+			return nil, usageErr("invalid client %q. Valid clients are: %s", name, clients.Names())
+		}
+		if !cfg.HasService(spec.Name) {
+			// Clients share the template's port; later ones get the next free one.
+			used := map[string]string{}
+			for _, s := range cfg.Services {
+				used[s.Port] = s.Name
+			}
+			cfg.AddService(spec.Name, freePort(spec.Port, used))
+		}
+	}
+	with, _ := cmd.Flags().GetStringSlice("with")
+	for _, name := range with {
+		if _, ok := integrations.Names[name]; !ok {
+			return nil, usageErr("invalid integration %q. Valid integrations are: stripe, s3, postmark", name)
+		}
+		if !contains(cfg.Integrations, name) {
+			cfg.Integrations = append(cfg.Integrations, name)
+		}
+	}
+	module, _ := cmd.Flags().GetString("module")
+	if module != "" && module != config.DefaultModule {
+		if err := gomod.Check(module); err != nil {
+			return nil, usageErr("invalid --module: %v", err)
+		}
+		cfg.Module = module
+	}
+	cfg.InfraPopulated, _ = cmd.Flags().GetBool("infra")
+	cfg.MonitoringPopulated, _ = cmd.Flags().GetBool("mon")
+	return cfg, nil
+}
+
+// renderTemplate shapes a template copy at dir like the project con
+// describes: clients, infra and monitoring it does not have are removed and
+// integrations it does not have are stripped, from the service and its
+// clients. init renders a fresh download this way; upgrade renders the old
+// and new templates so they compare with the project.
+func renderTemplate(dir string, con *config.Config) error {
+	var services []string
+	for _, s := range con.Services {
+		services = append(services, s.Name)
+	}
+	remove := []string{".git"}
+	for _, client := range clients.All() {
+		if !contains(services, client.Name) {
+			remove = append(remove, filepath.Join("app", client.ServiceDir), client.ComposeFile)
+		}
+	}
+	if !clients.HasAny(con) {
+		remove = append(remove, "e2e")
+	}
+	if !con.InfraPopulated {
+		remove = append(remove, "infra", ".github")
+	}
+	if !con.MonitoringPopulated {
+		remove = append(remove, "monitoring", "docker-compose.monitoring.yml", filepath.Join("infra", "monitoring.tf"))
+	}
+	for _, path := range remove {
+		if err := os.RemoveAll(filepath.Join(dir, path)); err != nil {
+			return err
+		}
+	}
+
+	strips := []struct {
+		name   string
+		strip  func(string) error
+		client func(string, string) error
+	}{
+		{"stripe", integrations.StripeStrip, integrations.StripeStripClient},
+		{"s3", integrations.S3Strip, integrations.S3StripClient},
+		{"postmark", integrations.PostmarkStrip, integrations.PostmarkStripClient},
+	}
+	for _, s := range strips {
+		if contains(con.Integrations, s.name) {
+			continue
+		}
+		if err := s.strip(dir); err != nil {
+			return fmt.Errorf("stripping %s: %w", s.name, err)
+		}
+		for _, client := range clients.Enabled(con) {
+			if err := s.client(client.Name, filepath.Join(dir, "app", client.ServiceDir)); err != nil {
+				return fmt.Errorf("stripping %s from %s: %w", s.name, client.DisplayName, err)
+			}
+		}
+	}
+
+	composeFiles := []string{"docker-compose.yml", "docker-compose.monitoring.yml"}
+	for _, client := range clients.All() {
+		composeFiles = append(composeFiles, client.ComposeFile)
+	}
+	for _, name := range composeFiles {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(renameProject(string(content), con.ProjectName)), 0o644); err != nil {
+			return err
+		}
+	}
+
+	if err := renderPorts(dir, con); err != nil {
+		return err
+	}
+
+	if module := con.GoModule(); module != config.DefaultModule {
+		if err := gomod.Rewrite(dir, config.DefaultModule, module); err != nil {
+			return fmt.Errorf("setting module path: %w", err)
+		}
+	}
+
+	// The project was formatted after stripping; format the template the
+	// same way so formatting alone never shows up as a change.
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if formatted, err := format.Source(content); err == nil && !bytes.Equal(formatted, content) {
+			return os.WriteFile(path, formatted, 0o644)
+		}
+		return nil
+	})
+}
+
+// renameProject replaces the template's project name in a compose file:
+// every "gofast" that stands on its own or starts or ends a name such as
+// gofast-postgres or gofast_data, but not the gofast-live organisation or the
+// gofast.live domain.
+func renameProject(content, projectName string) string {
+	const name = "gofast"
+	var b strings.Builder
+	last := 0
+	for {
+		i := strings.Index(content[last:], name)
+		if i < 0 {
+			break
+		}
+		start := last + i
+		end := start + len(name)
+		rest := content[end:]
+		switch {
+		case start > 0 && isNameChar(content[start-1]),
+			end < len(content) && isNameChar(content[end]),
+			strings.HasPrefix(rest, "-live"), strings.HasPrefix(rest, ".live"):
+			b.WriteString(content[last:end])
+		default:
+			b.WriteString(content[last:start])
+			b.WriteString(projectName)
+		}
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+func isNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(markersCmd)
+	markersCmd.AddCommand(markersCheckCmd)
+	markersCheckCmd.Flags().Bool("fix", false, "Repair trivially fixable problems (missing half of a marker pair, repeated marker lines)")
+}
+
+var markersCmd = &cobra.Command{
+	Use:   "markers",
+	Short: "Inspect the GF_ markers the CLI relies on",
+	Long:  "Inspect the GF_ marker comments that code generation uses as insertion points in your GoFast project.",
+}
+
+var markersCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the project for missing, duplicated, unbalanced or out-of-order markers",
+	Long: `Scan the project for every marker 'gof model' and 'gof add' rely on and report
+missing, duplicated, unbalanced or out-of-order markers with file:line.
+
+Errors make generation fail or produce wrong code. Warnings mean a preferred
+insertion point is gone and the CLI falls back to a structural merge point.
+
+With --fix, a pair marker whose other half is missing gets it inserted right
+next to the existing half, and marker lines repeated on adjacent lines are
+removed. Everything else has to be fixed by hand.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.ParseConfig()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		rules := projectMarkerRules(cfg)
+
+		fix, _ := cmd.Flags().GetBool("fix")
+		if fix {
+			n, err := markers.Fix(".", rules)
+			if err != nil {
+				return failErr("fixing markers: %w", err)
+			}
+			if n > 0 {
+				cmd.Printf("Fixed %d marker problem(s).\n", n)
+			}
+		}
+
+		problems, err := markers.Check(".", rules)
+		if err != nil {
+			return failErr("checking markers: %w", err)
+		}
+		report.Data = problems
+		if len(problems) == 0 {
+			cmd.Println(config.SuccessStyle.Render("All markers are in place."))
+			return nil
+		}
+		printMarkerProblems(cmd, problems)
+		if !fix && hasFixable(problems) {
+			cmd.Printf("Run %s to repair the fixable ones.\n", config.SuccessStyle.Render("'gof markers check --fix'"))
+		}
+		if markers.HasFailures(problems) {
+			return failErr("marker check found errors")
+		}
+		return nil
+	},
+}
+
+// projectMarkerRules returns the marker rules for every Go service of cfg.
+func projectMarkerRules(cfg *config.Config) []markers.Rule {
+	var pkgs []string
+	for _, s := range cfg.Services {
+		if _, client := clients.SpecFor(s.Name); !client {
+			pkgs = append(pkgs, servicePackage(s.Name))
+		}
+	}
+	return markers.RulesFor(pkgs...)
+}
+
+// preflightMarkers runs the marker check before generating into the Go
+// service svc (e.g. service-core). It prints every problem and returns an
+// error when an error-level problem would make the command fail halfway.
+func preflightMarkers(cmd *cobra.Command, svc string) error {
+	problems, err := markers.Check(".", markers.RulesFor(svc))
+	if err != nil {
+		return genErr("checking markers: %w", err)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	printMarkerProblems(cmd, problems)
+	if markers.HasFailures(problems) {
+		cmd.Printf("Run %s for details and repairs.\n", config.SuccessStyle.Render("'gof markers check --fix'"))
+		return genErr("marker problems would break generation; nothing was changed")
+	}
+	return nil
+}
+
+func printMarkerProblems(cmd *cobra.Command, problems []markers.Problem) {
+	for _, p := range problems {
+		line := p.String()
+		if p.Severity == markers.Failure {
+			line = config.ErrStyle.Render(line)
+		}
+		if p.Fixable {
+			line += " (fixable)"
+		}
+		cmd.Printf("  %s\n", line)
+	}
+}
+
+func hasFixable(problems []markers.Problem) bool {
+	for _, p := range problems {
+		if p.Fixable {
+			return true
+		}
+	}
+	return false
+}
@@ -11,11 +11,13 @@ import (
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/e2e"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/goedit"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(modelCmd)
+	modelCmd.Flags().String("service", coreService, "Go service to generate the model into (see 'gof service new')")
 }
 
 type Column struct {
@@ -59,22 +61,38 @@ Valid column types are:
   - date    (PostgreSQL: timestamptz)
   - bool    (PostgreSQL: boolean)
 
+With --service the domain and transport packages are generated into that Go
+service (app/service-<name>) and wired into its main.go, and its RPCs into
+that service's proto package, proto/<name>/v1. The migration and queries stay
+in service-core, which owns the database. Client pages are only
+generated for models of service-core, since the clients talk to it.
+
 Example:
   gof model post title:string content:string views:number published_at:date is_published:bool
+  gof model invoice amount:number paid:bool --service billing
 `,
 	Args: cobra.MinimumNArgs(2),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		_, _, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
 		// Ensure we are inside a valid gofast project (has gofast.json)
-		con, err := config.ParseConfig()
+		con, err := config.Open()
 		if err != nil {
-			cmd.Printf("%v\n", err)
-			return
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+
+		service, _ := cmd.Flags().GetString("service")
+		if err := checkBackendService(con.Config, service); err != nil {
+			return err
+		}
+		svc := servicePackage(service)
+
+		if err := preflightMarkers(cmd, svc); err != nil {
+			return err
 		}
 
 		modelName := args[0]
@@ -82,17 +100,15 @@ Example:
 		// Validate model name: must be lowercase letters and underscores only
 		validModelName := regexp.MustCompile(`^[a-z][a-z_]*$`)
 		if !validModelName.MatchString(modelName) {
-			cmd.Println("Error: Invalid model name. Must start with a lowercase letter and contain only lowercase letters and underscores.")
 			cmd.Println("Example: gof model note title:string content:string")
-			return
+			return usageErr("invalid model name '%s'. Must start with a lowercase letter and contain only lowercase letters and underscores", modelName)
 		}
 
 		// Reject plural model names to avoid generation issues
 		if pluralizeClient.IsPlural(modelName) {
 			singular := pluralizeClient.Singular(modelName)
-			cmd.Printf("Error: Model name '%s' appears to be plural. Use the singular form instead.\n", modelName)
 			cmd.Printf("Suggestion: gof model %s ...\n", singular)
-			return
+			return usageErr("model name '%s' appears to be plural. Use the singular form instead", modelName)
 		}
 
 		columnStrings := args[1:]
@@ -127,47 +143,39 @@ Example:
 		for _, colStr := range columnStrings {
 			parts := strings.Split(colStr, ":")
 			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-				cmd.Printf("Error: Invalid column format '%s'. Use name:type.\n", colStr)
-				return
+				return usageErr("invalid column format '%s'. Use name:type", colStr)
 			}
 
 			colName := parts[0]
 
 			// Validate column name format
 			if !validColName.MatchString(colName) {
-				cmd.Printf("Error: Invalid column name '%s'. Must start with a lowercase letter and contain only lowercase letters, numbers, and underscores.\n", colName)
-				return
+				return usageErr("invalid column name '%s'. Must start with a lowercase letter and contain only lowercase letters, numbers, and underscores", colName)
 			}
 
 			// Check for reserved column names
 			if reservedColumns[colName] {
-				cmd.Printf("Error: Column name '%s' is reserved (auto-generated). Choose a different name.\n", colName)
-				return
+				return usageErr("column name '%s' is reserved (auto-generated). Choose a different name", colName)
 			}
 
 			// Check for Go keywords
 			if goKeywords[colName] {
-				cmd.Printf("Error: Column name '%s' is a Go reserved keyword. Choose a different name.\n", colName)
-				return
+				return usageErr("column name '%s' is a Go reserved keyword. Choose a different name", colName)
 			}
 
 			// Check for SQL keywords that would break generated migrations/queries
 			if sqlKeywords[colName] {
-				cmd.Printf("Error: Column name '%s' is a reserved SQL keyword. Choose a different name.\n", colName)
-				return
+				return usageErr("column name '%s' is a reserved SQL keyword. Choose a different name", colName)
 			}
 
 			colType := strings.ToLower(parts[1])
 			if !validTypes[colType] {
-				cmd.Printf("Error: Invalid type '%s' for column '%s'.\n", parts[1], colName)
-				cmd.Println("Valid types are: string, number, date, bool.")
-				return
+				return usageErr("invalid type '%s' for column '%s'. Valid types are: string, number, date, bool", parts[1], colName)
 			}
 
 			// Ensure column names are unique
 			if seenNames[colName] {
-				cmd.Printf("Error: Duplicate column name '%s'. Column names must be unique.\n", colName)
-				return
+				return usageErr("duplicate column name '%s'. Column names must be unique", colName)
 			}
 			seenNames[colName] = true
 
@@ -180,8 +188,7 @@ Example:
 
 		// min 2 columns
 		if counter < 2 {
-			cmd.Printf("Error: At least 2 columns are required, got %d.\n", counter)
-			return
+			return usageErr("at least 2 columns are required, got %d", counter)
 		}
 
 		configColumns := make([]config.Column, len(columns))
@@ -195,65 +202,70 @@ Example:
 		cmd.Println("")
 		cmd.Printf("Generating model '%s'...\n", modelName)
 
-		err = config.AddModel(modelName, configColumns)
+		modelService := service
+		if service == coreService {
+			modelService = ""
+		}
+		err = con.AddModel(modelName, modelService, configColumns)
 		if err != nil {
-			cmd.Printf("Error adding model: %v.\n", err)
-			return
+			return genErr("adding model: %w", err)
+		}
+		// Record the model before generating anything, so a run that fails
+		// half way is refused on retry instead of writing a second migration
+		// and duplicate query and proto blocks.
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
 		}
 
-		err = generateProto(modelName, columns)
+		err = generateProto(modelName, con.GoModule(), service, columns)
 		if err != nil {
-			cmd.Printf("Error generating proto: %v.\n", err)
-			return
+			return genErr("generating proto: %w", err)
 		}
 
 		migrationPath, err := generateSchema(modelName, columns)
 		if err != nil {
-			cmd.Printf("Error generating schema: %v.\n", err)
-			return
+			return genErr("generating schema: %w", err)
 		}
 
 		err = generateQueries(modelName, columns)
 		if err != nil {
-			cmd.Printf("Error generating queries: %v.\n", err)
-			return
+			return genErr("generating queries: %w", err)
 		}
 
 		// Add model-specific auth permissions before generating service layer
 		err = generateAuthAccessFlags(modelName)
 		if err != nil {
-			cmd.Printf("Error updating auth permissions: %v.\n", err)
-			return
+			return genErr("updating auth permissions: %w", err)
 		}
 
 		// Update seed_dev_user.sh with new permission value
-		err = e2e.UpdateSeedDevUser()
+		err = e2e.UpdateSeedDevUser(con.Config)
 		if err != nil {
-			cmd.Printf("Error updating seed script: %v.\n", err)
-			return
+			return genErr("updating seed script: %w", err)
 		}
 
-		err = generateServiceLayer(modelName, columns)
+		err = generateServiceLayer(modelName, con.GoModule(), svc, columns)
 		if err != nil {
-			cmd.Printf("Error generating service layer: %v.\n", err)
-			return
+			return genErr("generating service layer: %w", err)
 		}
 
 		// Generate ConnectRPC transport layer from skeleton template
-		err = generateTransportLayer(modelName, columns)
+		err = generateTransportLayer(modelName, con.GoModule(), svc, columns)
 		if err != nil {
-			cmd.Printf("Error generating transport layer: %v.\n", err)
-			return
+			return genErr("generating transport layer: %w", err)
 		}
 
 		// Wire new model into main.go (imports, deps init, route mounting)
-		err = wireCoreMain(modelName)
+		err = wireServiceMain(modelName, con.GoModule(), svc)
 		if err != nil {
-			cmd.Printf("Error wiring core main.go: %v.\n", err)
-			return
+			return genErr("wiring %s main.go: %w", svc, err)
 		}
 
-		enabledClients := clients.Enabled(con)
+		enabledClients := clients.Enabled(con.Config)
+		if service != coreService && len(enabledClients) > 0 {
+			warnf(cmd, "no client pages generated: clients only talk to service-core, not %s", svc)
+			enabledClients = nil
+		}
 		if len(enabledClients) > 0 {
 			e2eColumns := make([]e2e.Column, len(columns))
 			for i, col := range columns {
@@ -261,21 +273,18 @@ Example:
 			}
 			err = e2e.GenerateClientE2ETest(modelName, e2eColumns)
 			if err != nil {
-				cmd.Printf("Error generating client e2e test: %v.\n", err)
-				return
+				return genErr("generating client e2e test: %w", err)
 			}
 			for _, client := range enabledClients {
 				err = generateClientScaffolding(client.Name, modelName, configColumns)
 				if err != nil {
-					cmd.Printf("Error generating %s client pages: %v.\n", client.DisplayName, err)
-					return
+					return genErr("generating %s client pages: %w", client.DisplayName, err)
 				}
 			}
 			for _, client := range enabledClients {
-				err = formatClientProject(client.Name)
+				err = formatClientProject(client.Name, ".")
 				if err != nil {
-					cmd.Printf("Error formatting %s client: %v.\n", client.DisplayName, err)
-					return
+					return genErr("formatting %s client: %w", client.DisplayName, err)
 				}
 			}
 		}
@@ -290,33 +299,28 @@ Example:
 		cmd.Println("")
 		cmd.Println("Generated files:")
 		goPackageName := toGoPackageName(modelName)
-		cmd.Printf("  - Proto:     %s\n", config.SuccessStyle.Render("proto/v1/"+modelName+".proto"))
+		cmd.Printf("  - Proto:     %s\n", config.SuccessStyle.Render(serviceProtoDir(service)+"/"+modelName+".proto"))
 		cmd.Printf("  - Migration: %s\n", config.SuccessStyle.Render(migrationPath))
 		cmd.Printf("  - Queries:   %s\n", config.SuccessStyle.Render("app/service-core/storage/query.sql"))
-		cmd.Printf("  - Service:   %s\n", config.SuccessStyle.Render("app/service-core/domain/"+goPackageName))
-		cmd.Printf("  - Transport: %s\n", config.SuccessStyle.Render("app/service-core/transport/"+goPackageName))
+		cmd.Printf("  - Service:   %s\n", config.SuccessStyle.Render("app/"+svc+"/domain/"+goPackageName))
+		cmd.Printf("  - Transport: %s\n", config.SuccessStyle.Render("app/"+svc+"/transport/"+goPackageName))
 		for _, client := range enabledClients {
-			clientPath := "app/" + client.ServiceDir + "/src/routes"
-			switch client.Name {
-			case clients.Svelte:
-				clientPath += "/(app)/models/" + pluralizeClient.Plural(modelName)
-			case clients.Tanstack:
-				clientPath += "/_layout/models/" + pluralizeClient.Plural(modelName)
-			}
+			clientPath := "app/" + client.ServiceDir + "/" + client.ModelsRouteSubpath + "/" + pluralizeClient.Plural(modelName)
 			cmd.Printf("  - %s: %s\n", client.DisplayName+" client", config.SuccessStyle.Render(clientPath))
 		}
 		cmd.Println("")
 		cmd.Println("Next steps:")
-		cmd.Printf("  1. Run %s to regenerate SQL queries\n", config.SuccessStyle.Render("'make sql'"))
-		cmd.Printf("  2. Run %s to regenerate proto code\n", config.SuccessStyle.Render("'make gen'"))
-		cmd.Printf("  3. Run %s to format generated code\n", config.SuccessStyle.Render("'make format'"))
-		cmd.Printf("  4. Run %s to apply migrations\n", config.SuccessStyle.Render("'make migrate'"))
+		runStep(cmd, 1, "make sql", "to regenerate SQL queries")
+		runStep(cmd, 2, "make gen", "to regenerate proto code")
+		runStep(cmd, 3, "make format", "to format generated code")
+		runStep(cmd, 4, "make migrate", "to apply migrations")
 		cmd.Println("")
 		if len(enabledClients) > 0 {
 			cmd.Println("Add this route to your navigation:")
-			cmd.Printf("  %s\n", config.SuccessStyle.Render(clientModelPath(enabledClients[0].Name, modelName)))
+			printRoute(cmd, clientModelPath(enabledClients[0].Name, modelName))
 			cmd.Println("")
 		}
+		return nil
 	},
 }
 
@@ -380,8 +384,8 @@ func toGoVarName(s string) string {
 }
 
 // generateTransportTestContent generates transport test file by copying skeleton and replacing markers
-func generateTransportTestContent(modelName, capitalizedModelName string, columns []Column, pluralLower, pluralCap string) (string, error) {
-	templatePath := "./app/service-core/transport/skeleton/route_test.go"
+func generateTransportTestContent(modelName, module, svc, capitalizedModelName string, columns []Column, pluralLower, pluralCap string) (string, error) {
+	templatePath := "./app/" + svc + "/transport/skeleton/route_test.go"
 	contentBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("reading template file %s: %w", templatePath, err)
@@ -412,10 +416,15 @@ func generateTransportTestContent(modelName, capitalizedModelName string, column
 	editAssert := buildEditAssertFields(columns, capitalizedModelName)
 
 	// Replace marker regions
-	content = replaceMarkerRegion(content, "GF_TP_TEST_ENTITY_FIELDS_START", "GF_TP_TEST_ENTITY_FIELDS_END", entityFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_CREATE_FIELDS_START", "GF_TP_TEST_CREATE_FIELDS_END", createFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_EDIT_FIELDS_START", "GF_TP_TEST_EDIT_FIELDS_END", editFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_EDIT_ASSERT_START", "GF_TP_TEST_EDIT_ASSERT_END", editAssert)
+	content, err = replaceMarkerRegions(content, map[string]string{
+		"TP_TEST_ENTITY_FIELDS": entityFields,
+		"TP_TEST_CREATE_FIELDS": createFields,
+		"TP_TEST_EDIT_FIELDS":   editFields,
+		"TP_TEST_EDIT_ASSERT":   editAssert,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", templatePath, err)
+	}
 
 	// Go naming conversions
 	goPackageName := toGoPackageName(modelName)
@@ -427,9 +436,9 @@ func generateTransportTestContent(modelName, capitalizedModelName string, column
 	content = strings.ReplaceAll(content, "Skeleton", capitalizedModelName)
 	content = strings.Replace(content, "package skeleton", "package "+goPackageName, 1)
 	// Fix import paths to use goPackageName (lowercase directory) with proper aliases
-	content = strings.Replace(content, `skeletonSvc "gofast/service-core/domain/skeleton"`, goVarName+`Svc "gofast/service-core/domain/`+goPackageName+`"`, 1)
+	content = strings.Replace(content, `skeletonSvc "`+module+`/`+svc+`/domain/skeleton"`, goVarName+`Svc "`+module+`/`+svc+`/domain/`+goPackageName+`"`, 1)
 	// Add alias to transport import since skeleton.Server becomes userProfile.Server after replacement
-	content = strings.Replace(content, `"gofast/service-core/transport/skeleton"`, goVarName+` "gofast/service-core/transport/`+goPackageName+`"`, 1)
+	content = strings.Replace(content, `"`+module+`/`+svc+`/transport/skeleton"`, goVarName+` "`+module+`/`+svc+`/transport/`+goPackageName+`"`, 1)
 	content = strings.ReplaceAll(content, "skeletons", pluralVarName)
 	content = strings.ReplaceAll(content, "skeleton", goVarName)
 
@@ -507,15 +516,18 @@ func generateAuthAccessFlags(modelName string) error {
 	return nil
 }
 
-// wireCoreMain injects imports, deps initialization, and route mounting for
-// a new model into ./app/service-core/main.go using marker regions.
-func wireCoreMain(modelName string) error {
-	path := "./app/service-core/main.go"
-	b, err := os.ReadFile(path)
+// wireServiceMain injects imports, deps initialization, and route mounting for
+// a new model into ./app/<svc>/main.go. Merge points are resolved on the
+// syntax tree: the GF_MAIN_* marker comments are preferred, with fallbacks to the
+// import declaration, the last Deps initialization and the last server.Mount call.
+// Services other than core mount handlers of their own proto package, imported
+// as <name>connect.
+func wireServiceMain(modelName, module, svc string) error {
+	path := "./app/" + svc + "/main.go"
+	f, err := goedit.ParseFile(path)
 	if err != nil {
-		return fmt.Errorf("reading core main.go: %w", err)
+		return fmt.Errorf("reading %s main.go: %w", svc, err)
 	}
-	s := string(b)
 
 	// Go naming conversions
 	goPackageName := toGoPackageName(modelName)
@@ -524,68 +536,81 @@ func wireCoreMain(modelName string) error {
 	svcAlias := goVarName + "Svc"
 	routeAlias := goVarName + "Route"
 
-	// Import lines (use goPackageName for paths, goVarName for aliases)
-	svcImportLine := "\t" + svcAlias + " \"gofast/service-core/domain/" + goPackageName + "\""
-	routeImportLine := "\t" + routeAlias + " \"gofast/service-core/transport/" + goPackageName + "\""
+	// Import paths (use goPackageName for paths, goVarName for aliases)
+	svcImportPath := module + "/" + svc + "/domain/" + goPackageName
+	routeImportPath := module + "/" + svc + "/transport/" + goPackageName
 
 	// Deps initialization (use goVarName for variable names)
-	depsInitLine := "\t" + goVarName + "Deps := " + svcAlias + ".Deps{Store: store}"
+	depsInitLine := goVarName + "Deps := " + svcAlias + ".Deps{Store: store}"
 
 	// Route mounting (3 lines)
 	routeMountLines := strings.Join([]string{
-		"\t" + goVarName + "Server := " + routeAlias + ".New" + cap + "Server(" + goVarName + "Deps)",
-		"\tpath, handler = v1connect.New" + cap + "ServiceHandler(" + goVarName + "Server, server.Interceptors())",
-		"\tserver.Mount(path, handler)",
+		goVarName + "Server := " + routeAlias + ".New" + cap + "Server(" + goVarName + "Deps)",
+		"path, handler = v1connect.New" + cap + "ServiceHandler(" + goVarName + "Server, server.Interceptors())",
+		"server.Mount(path, handler)",
 	}, "\n")
 
-	// Append unique lines to regions
-	appendUniqueLine := func(content, startMarker, endMarker, line string) (string, error) {
-		sidx := strings.Index(content, startMarker)
-		eidx := strings.Index(content, endMarker)
-		if sidx == -1 || eidx == -1 || eidx <= sidx {
-			return content, fmt.Errorf("markers %q..%q not found", startMarker, endMarker)
-		}
-		// Find region bounds: after start line to start of end line
-		startLineEndRel := strings.Index(content[sidx:], "\n")
-		if startLineEndRel == -1 {
-			return content, fmt.Errorf("cannot locate end of start marker line for %s", startMarker)
-		}
-		regionStart := sidx + startLineEndRel + 1
-		endLineStart := strings.LastIndex(content[:eidx], "\n") + 1
-		region := content[regionStart:endLineStart]
-		if strings.Contains(region, strings.TrimSpace(strings.Split(line, "\n")[0])) {
-			return content, nil
+	imports, err := f.ImportScope()
+	if err != nil {
+		return fmt.Errorf("adding imports: %w", err)
+	}
+	if name := strings.TrimPrefix(svc, "service-"); name != coreService {
+		connectAlias := name + "connect"
+		connectImportPath := module + "/gen/" + serviceProtoDir(name) + "/v1connect"
+		routeMountLines = strings.Join([]string{
+			goVarName + "Server := " + routeAlias + ".New" + cap + "Server(" + goVarName + "Deps)",
+			"server.Mount(" + connectAlias + ".New" + cap + "ServiceHandler(" + goVarName + "Server, server.Interceptors()))",
+		}, "\n")
+		if !f.HasImport(connectImportPath) {
+			if err := f.Insert(imports, connectAlias+" \""+connectImportPath+"\"", "proto import",
+				goedit.BeforeComment("GF_MAIN_IMPORT_SERVICES_START"),
+				goedit.AtEnd(),
+			); err != nil {
+				return fmt.Errorf("adding proto import: %w", err)
+			}
 		}
-		if region != "" && !strings.HasSuffix(region, "\n") {
-			region += "\n"
+	}
+	if !f.HasImport(svcImportPath) {
+		if err := f.Insert(imports, svcAlias+" \""+svcImportPath+"\"", "service import",
+			goedit.BeforeComment("GF_MAIN_IMPORT_SERVICES_END"),
+			goedit.AtEnd(),
+		); err != nil {
+			return fmt.Errorf("adding service import: %w", err)
 		}
-		if !strings.HasSuffix(line, "\n") {
-			line += "\n"
+	}
+	if !f.HasImport(routeImportPath) {
+		if err := f.Insert(imports, routeAlias+" \""+routeImportPath+"\"", "route import",
+			goedit.BeforeComment("GF_MAIN_IMPORT_ROUTES_END"),
+			goedit.AtEnd(),
+		); err != nil {
+			return fmt.Errorf("adding route import: %w", err)
 		}
-		region += line
-		return content[:regionStart] + region + content[endLineStart:], nil
 	}
 
-	var aerr error
-	s, aerr = appendUniqueLine(s, "GF_MAIN_IMPORT_SERVICES_START", "GF_MAIN_IMPORT_SERVICES_END", svcImportLine)
-	if aerr != nil {
-		return fmt.Errorf("adding service import: %w", aerr)
-	}
-	s, aerr = appendUniqueLine(s, "GF_MAIN_IMPORT_ROUTES_START", "GF_MAIN_IMPORT_ROUTES_END", routeImportLine)
-	if aerr != nil {
-		return fmt.Errorf("adding route import: %w", aerr)
+	body, err := f.FuncScope("main")
+	if err != nil {
+		return fmt.Errorf("wiring model: %w", err)
 	}
-	s, aerr = appendUniqueLine(s, "GF_MAIN_INIT_SERVICES_START", "GF_MAIN_INIT_SERVICES_END", depsInitLine)
-	if aerr != nil {
-		return fmt.Errorf("adding deps init: %w", aerr)
+	if !f.ContainsNode(body, depsInitLine) {
+		if err := f.Insert(body, depsInitLine, "deps initialization",
+			goedit.BeforeComment("GF_MAIN_INIT_SERVICES_END"),
+			goedit.AfterLastNode(goedit.AssignsComposite("Deps")),
+		); err != nil {
+			return fmt.Errorf("adding deps init: %w", err)
+		}
 	}
-	s, aerr = appendUniqueLine(s, "GF_MAIN_MOUNT_ROUTES_START", "GF_MAIN_MOUNT_ROUTES_END", routeMountLines)
-	if aerr != nil {
-		return fmt.Errorf("adding route mount: %w", aerr)
+	serverLine := strings.SplitN(routeMountLines, "\n", 2)[0]
+	if !f.ContainsNode(body, serverLine) {
+		if err := f.Insert(body, routeMountLines, "route mount",
+			goedit.BeforeComment("GF_MAIN_MOUNT_ROUTES_END"),
+			goedit.AfterLastNode(goedit.CallsSelector("server", "Mount")),
+		); err != nil {
+			return fmt.Errorf("adding route mount: %w", err)
+		}
 	}
 
-	if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
-		return fmt.Errorf("writing core main.go: %w", err)
+	if err := f.WriteFile(path); err != nil {
+		return fmt.Errorf("writing %s main.go: %w", svc, err)
 	}
 	return nil
 }
@@ -9,8 +9,10 @@ import (
 	"strings"
 )
 
-func generateProto(modelName string, columns []Column) error {
-	protoDir := "./proto/v1"
+// generateProto adds the model's messages and service to the proto package
+// of Go service name (see serviceProtoDir).
+func generateProto(modelName, module, service string, columns []Column) error {
+	protoDir := serviceProtoDir(service)
 
 	if err := os.MkdirAll(protoDir, 0o755); err != nil {
 		return err
@@ -30,9 +32,7 @@ func generateProto(modelName string, columns []Column) error {
 	modelProtoPath := filepath.Join(protoDir, modelName+".proto")
 	if _, err := os.Stat(modelProtoPath); err != nil {
 		var b strings.Builder
-		b.WriteString("syntax = \"proto3\";\n")
-		b.WriteString("option go_package = \"gofast/gen/proto/v1\";\n")
-		b.WriteString("package proto.v1;\n\n")
+		b.WriteString(protoHeader(module, protoDir) + "\n")
 		b.WriteString("message " + capitalizedModelName + " {\n")
 		b.WriteString("    string id = 1;\n")
 		b.WriteString("    string created = 2;\n")
@@ -57,12 +57,16 @@ func generateProto(modelName string, columns []Column) error {
 	// 2) Update main.proto: add import, messages, and service
 	mainProtoPath := filepath.Join(protoDir, "main.proto")
 	mainBytes, err := os.ReadFile(mainProtoPath)
+	if os.IsNotExist(err) && service != coreService {
+		// Services created before they had their own package.
+		mainBytes, err = []byte(protoHeader(module, protoDir)), nil
+	}
 	if err != nil {
 		return err
 	}
 	mainContent := string(mainBytes)
 
-	importLine := fmt.Sprintf("import \"proto/v1/%s.proto\";", modelName)
+	importLine := fmt.Sprintf("import \"%s/%s.proto\";", protoDir, modelName)
 	if !strings.Contains(mainContent, importLine) {
 		lines := strings.Split(mainContent, "\n")
 		insertIdx := 0
@@ -4,11 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-func generateServiceContent(modelName string, capitalizedModelName string) (string, error) {
-	templatePath := "./app/service-core/domain/skeleton/service.go"
+func generateServiceContent(modelName, svc string, capitalizedModelName string) (string, error) {
+	templatePath := "./app/" + svc + "/domain/skeleton/service.go"
 	contentBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("reading template file %s: %w", templatePath, err)
@@ -35,10 +36,12 @@ func generateServiceContent(modelName string, capitalizedModelName string) (stri
 	return content, nil
 }
 
-func generateServiceLayer(modelName string, columns []Column) error {
-	sourceDir := "./app/service-core/domain/skeleton"
+// generateServiceLayer scaffolds the domain package of a model in the service
+// directory svc (e.g. "service-core") from that service's skeleton.
+func generateServiceLayer(modelName, module, svc string, columns []Column) error {
+	sourceDir := "./app/" + svc + "/domain/skeleton"
 	goPackageName := toGoPackageName(modelName)
-	destDir := "app/service-core/domain/" + goPackageName
+	destDir := "app/" + svc + "/domain/" + goPackageName
 	capitalizedModelName := capitalize(modelName)
 
 	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
@@ -56,13 +59,13 @@ func generateServiceLayer(modelName string, columns []Column) error {
 		var newContentStr string
 		var genErr error
 		if info.Name() == "service.go" {
-			newContentStr, genErr = generateServiceContent(modelName, capitalizedModelName)
+			newContentStr, genErr = generateServiceContent(modelName, svc, capitalizedModelName)
 		} else if info.Name() == "service_test.go" {
-			newContentStr, genErr = generateServiceTestContent(modelName, capitalizedModelName, columns)
+			newContentStr, genErr = generateServiceTestContent(modelName, module, svc, capitalizedModelName, columns)
 		} else if info.Name() == "validation.go" {
-			newContentStr, genErr = generateValidationContent(modelName, capitalizedModelName, columns)
+			newContentStr, genErr = generateValidationContent(modelName, module, capitalizedModelName, columns)
 		} else if info.Name() == "validation_test.go" {
-			newContentStr, genErr = generateValidationTestContent(modelName, capitalizedModelName, columns)
+			newContentStr, genErr = generateValidationTestContent(modelName, module, svc, capitalizedModelName, columns)
 		} else {
 			content, err := os.ReadFile(path)
 			if err != nil {
@@ -82,16 +85,17 @@ func generateServiceLayer(modelName string, columns []Column) error {
 			return fmt.Errorf("generating content for %s: %w", destPath, genErr)
 		}
 
+		newContentStr = serviceProtoImports(newContentStr, module, svc)
 		return os.WriteFile(destPath, []byte(newContentStr), info.Mode())
 	})
 }
 
 // generateTransportLayer scaffolds ConnectRPC handlers by copying the transport
 // skeleton and performing token replacements for singular/plural variants.
-func generateTransportLayer(modelName string, columns []Column) error {
-	sourceDir := "./app/service-core/transport/skeleton"
+func generateTransportLayer(modelName, module, svc string, columns []Column) error {
+	sourceDir := "./app/" + svc + "/transport/skeleton"
 	goPackageName := toGoPackageName(modelName)
-	destDir := "app/service-core/transport/" + goPackageName
+	destDir := "app/" + svc + "/transport/" + goPackageName
 
 	capitalizedModelName := capitalize(modelName)
 	pluralLower := pluralizeClient.Plural(modelName)
@@ -113,9 +117,9 @@ func generateTransportLayer(modelName string, columns []Column) error {
 		var genErr error
 		switch info.Name() {
 		case "route.go":
-			newContentStr, genErr = generateTransportRouteContent(modelName, capitalizedModelName, pluralLower, pluralCap, columns)
+			newContentStr, genErr = generateTransportRouteContent(modelName, module, svc, capitalizedModelName, pluralLower, pluralCap, columns)
 		case "route_test.go":
-			newContentStr, genErr = generateTransportTestContent(modelName, capitalizedModelName, columns, pluralLower, pluralCap)
+			newContentStr, genErr = generateTransportTestContent(modelName, module, svc, capitalizedModelName, columns, pluralLower, pluralCap)
 		default:
 			content, readErr := os.ReadFile(path)
 			if readErr != nil {
@@ -134,12 +138,13 @@ func generateTransportLayer(modelName string, columns []Column) error {
 		if genErr != nil {
 			return fmt.Errorf("generating transport content for %s: %w", destPath, genErr)
 		}
+		newContentStr = serviceProtoImports(newContentStr, module, svc)
 		return os.WriteFile(destPath, []byte(newContentStr), info.Mode())
 	})
 }
 
-func generateTransportRouteContent(modelName, capitalizedModelName, pluralLower, pluralCap string, columns []Column) (string, error) {
-	templatePath := "./app/service-core/transport/skeleton/route.go"
+func generateTransportRouteContent(modelName, module, svc, capitalizedModelName, pluralLower, pluralCap string, columns []Column) (string, error) {
+	templatePath := "./app/" + svc + "/transport/skeleton/route.go"
 	contentBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("reading template file %s: %w", templatePath, err)
@@ -154,8 +159,8 @@ func generateTransportRouteContent(modelName, capitalizedModelName, pluralLower,
 	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
 	s = strings.Replace(s, "package skeleton", "package "+goPackageName, 1)
-	// Replace import path with alias: goVarName "gofast/service-core/domain/goPackageName"
-	s = strings.Replace(s, `"gofast/service-core/domain/skeleton"`, goVarName+` "gofast/service-core/domain/`+goPackageName+`"`, 1)
+	// Replace import path with alias: goVarName "<module>/<svc>/domain/goPackageName"
+	s = strings.Replace(s, `"`+module+`/`+svc+`/domain/skeleton"`, goVarName+` "`+module+`/`+svc+`/domain/`+goPackageName+`"`, 1)
 	s = strings.ReplaceAll(s, "skeletons", pluralVarName)
 	s = strings.ReplaceAll(s, "skeleton", goVarName)
 	// Rename leftover template-local variable names
@@ -215,7 +220,7 @@ func generateTransportRouteContent(modelName, capitalizedModelName, pluralLower,
 	return s, nil
 }
 
-func generateValidationContent(modelName string, capitalizedModelName string, columns []Column) (string, error) {
+func generateValidationContent(modelName, module string, capitalizedModelName string, columns []Column) (string, error) {
 	// Determine which imports are needed based on column types
 	needStrconv := false
 	needStr := false
@@ -231,12 +236,13 @@ func generateValidationContent(modelName string, capitalizedModelName string, co
 	// Build imports
 	imports := make([]string, 0, 5)
 	if needStr {
-		imports = append(imports, "\"gofast/pkg/str\"")
+		imports = append(imports, strconv.Quote(module+"/pkg/str"))
 	}
 	imports = append(imports,
-		"\"gofast/pkg\"",
-		"\"gofast/service-core/storage/query\"",
-		"proto \"gofast/gen/proto/v1\"",
+		strconv.Quote(module+"/pkg"),
+		// Every service uses core's storage package.
+		strconv.Quote(module+"/service-core/storage/query"),
+		"proto "+strconv.Quote(module+"/gen/proto/v1"),
 		"\"github.com/google/uuid\"",
 	)
 	if needStrconv {
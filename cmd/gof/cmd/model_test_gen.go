@@ -3,12 +3,15 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
 )
 
 // generateServiceTestContent generates test file by copying skeleton and replacing markers
-func generateServiceTestContent(modelName, capitalizedModelName string, columns []Column) (string, error) {
-	templatePath := "./app/service-core/domain/skeleton/service_test.go"
+func generateServiceTestContent(modelName, module, svc, capitalizedModelName string, columns []Column) (string, error) {
+	templatePath := "./app/" + svc + "/domain/skeleton/service_test.go"
 	contentBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("reading template file %s: %w", templatePath, err)
@@ -52,10 +55,15 @@ func generateServiceTestContent(modelName, capitalizedModelName string, columns
 	invalidFields := buildInvalidProtoFields(columns)
 
 	// Replace marker regions
-	content = replaceMarkerRegion(content, "GF_TP_TEST_ENTITY_FIELDS_START", "GF_TP_TEST_ENTITY_FIELDS_END", entityFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_CREATE_FIELDS_START", "GF_TP_TEST_CREATE_FIELDS_END", createFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_EDIT_FIELDS_START", "GF_TP_TEST_EDIT_FIELDS_END", editFields)
-	content = replaceMarkerRegion(content, "GF_TP_TEST_INVALID_FIELDS_START", "GF_TP_TEST_INVALID_FIELDS_END", invalidFields)
+	content, err = replaceMarkerRegions(content, map[string]string{
+		"TP_TEST_ENTITY_FIELDS":  entityFields,
+		"TP_TEST_CREATE_FIELDS":  createFields,
+		"TP_TEST_EDIT_FIELDS":    editFields,
+		"TP_TEST_INVALID_FIELDS": invalidFields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", templatePath, err)
+	}
 
 	// Go naming conversions
 	goPackageName := toGoPackageName(modelName)
@@ -69,7 +77,7 @@ func generateServiceTestContent(modelName, capitalizedModelName string, columns
 	content = strings.ReplaceAll(content, "Skeleton", capitalizedModelName)
 	content = strings.Replace(content, "package skeleton", "package "+goPackageName, 1)
 	// Fix import path to use goPackageName (lowercase directory) with alias
-	content = strings.Replace(content, `"gofast/service-core/domain/skeleton"`, goVarName+` "gofast/service-core/domain/`+goPackageName+`"`, 1)
+	content = strings.Replace(content, `"`+module+`/`+svc+`/domain/skeleton"`, goVarName+` "`+module+`/`+svc+`/domain/`+goPackageName+`"`, 1)
 	content = strings.ReplaceAll(content, "skeletons", pluralVarName)
 	content = strings.ReplaceAll(content, "skeleton", goVarName)
 
@@ -92,7 +100,7 @@ func buildEntityFields(columns []Column) string {
 			lines = append(lines, fmt.Sprintf("%s: true,", field))
 		}
 	}
-	return strings.Join(lines, "\n\t\t")
+	return strings.Join(lines, "\n")
 }
 
 // buildCreateProtoFields generates proto fields for create request (full version)
@@ -111,7 +119,7 @@ func buildCreateProtoFields(columns []Column, modelName string) string {
 			lines = append(lines, fmt.Sprintf("%s: true,", field))
 		}
 	}
-	return strings.Join(lines, "\n\t\t\t\t")
+	return strings.Join(lines, "\n")
 }
 
 // buildInvalidProtoFields generates proto fields with invalid values for validation error tests
@@ -130,7 +138,7 @@ func buildInvalidProtoFields(columns []Column) string {
 			// bools don't have invalid values, skip or use false
 		}
 	}
-	return strings.Join(lines, "\n\t\t\t\t")
+	return strings.Join(lines, "\n")
 }
 
 // buildEditProtoFields generates proto fields for edit request
@@ -149,7 +157,7 @@ func buildEditProtoFields(columns []Column) string {
 			lines = append(lines, fmt.Sprintf("%s: false,", field))
 		}
 	}
-	return strings.Join(lines, "\n\t\t\t\t")
+	return strings.Join(lines, "\n")
 }
 
 // buildEditAssertFields generates assertion lines for the edit transport test
@@ -169,58 +177,29 @@ func buildEditAssertFields(columns []Column, modelName string) string {
 			lines = append(lines, fmt.Sprintf("assert.Equal(t, false, res.Msg.Get%s().%s)", modelName, getter))
 		}
 	}
-	return strings.Join(lines, "\n\t\t")
+	return strings.Join(lines, "\n")
 }
 
-// replaceMarkerRegion replaces content between START and END markers (removes markers)
-func replaceMarkerRegion(content, startMarker, endMarker, replacement string) string {
-	for {
-		startIdx := strings.Index(content, startMarker)
-		if startIdx == -1 {
-			break
-		}
-		endIdx := strings.Index(content[startIdx:], endMarker)
-		if endIdx == -1 {
-			break
-		}
-		endIdx += startIdx
-
-		// Find the start of the start marker line
-		startLineStart := strings.LastIndex(content[:startIdx], "\n") + 1
-
-		// Find the newline after end marker
-		endLineEnd := strings.Index(content[endIdx:], "\n")
-		if endLineEnd == -1 {
-			endLineEnd = len(content) - endIdx
-		}
-		endLineEnd += endIdx + 1
-
-		// Detect indent from the start marker line (tabs only, exclude comment prefix)
-		indent := "\t\t"
-		if startLineStart < startIdx {
-			linePrefix := content[startLineStart:startIdx]
-			// Extract only whitespace (tabs/spaces), not comment characters
-			tabsOnly := ""
-			for _, ch := range linePrefix {
-				if ch == '\t' || ch == ' ' {
-					tabsOnly += string(ch)
-				} else {
-					break
-				}
-			}
-			indent = tabsOnly
+// replaceMarkerRegions replaces each GF_<name>_START/END region in content with
+// its body (removing the markers), indented to match the START marker.
+func replaceMarkerRegions(content string, bodies map[string]string) (string, error) {
+	names := make([]string, 0, len(bodies))
+	for name := range bodies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var err error
+		content, _, err = markers.Replace(content, []markers.Style{markers.Slash}, name, bodies[name])
+		if err != nil {
+			return "", err
 		}
-
-		// Build replacement with proper indent
-		replacementIndented := indent + replacement + "\n"
-
-		content = content[:startLineStart] + replacementIndented + content[endLineEnd:]
 	}
-	return content
+	return content, nil
 }
 
-func generateValidationTestContent(modelName, capitalizedModelName string, columns []Column) (string, error) {
-	templatePath := "./app/service-core/domain/skeleton/validation_test.go"
+func generateValidationTestContent(modelName, module, svc, capitalizedModelName string, columns []Column) (string, error) {
+	templatePath := "./app/" + svc + "/domain/skeleton/validation_test.go"
 	contentBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("reading template file %s: %w", templatePath, err)
@@ -262,53 +241,31 @@ func generateValidationTestContent(modelName, capitalizedModelName string, colum
 		editFields = append(editFields, fmt.Sprintf("%s: %s,", field, vn))
 	}
 
-	// Render into fixtures region
-	lines := strings.Split(string(contentBytes), "\n")
-	var out []string
-	for i := 0; i < len(lines); i++ {
-		line := lines[i]
-		trimmed := strings.TrimSpace(line)
-		switch trimmed {
-		case "// GF_FIXTURES_START":
-			out = append(out, line)
-			indent := strings.Repeat("\t", strings.Count(line, "\t"))
-			// makeCreate<Model>Proto
-			out = append(out, indent+fmt.Sprintf("func makeCreate%sProto(%s) *proto.%s {", capitalizedModelName, strings.Join(createParams, ", "), capitalizedModelName))
-			out = append(out, indent+"\treturn &proto."+capitalizedModelName+"{")
-			out = append(out, indent+"\t\tId: \"\",")
-			out = append(out, indent+"\t\tCreated: \"\",")
-			out = append(out, indent+"\t\tUpdated: \"\",")
-			for _, f := range createFields {
-				out = append(out, indent+"\t\t"+f)
-			}
-			out = append(out, indent+"\t}")
-			out = append(out, indent+"}")
-			out = append(out, "")
-
-			// makeEdit<Model>Proto
-			out = append(out, indent+fmt.Sprintf("func makeEdit%sProto(%s) *proto.%s {", capitalizedModelName, strings.Join(editParams, ", "), capitalizedModelName))
-			out = append(out, indent+"\treturn &proto."+capitalizedModelName+"{")
-			out = append(out, indent+"\t\tId: id,")
-			out = append(out, indent+"\t\tCreated: \"\",")
-			out = append(out, indent+"\t\tUpdated: \"\",")
-			for _, f := range editFields {
-				out = append(out, indent+"\t\t"+f)
-			}
-			out = append(out, indent+"\t}")
-			out = append(out, indent+"}")
-
-			// Skip lines until END
-			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "// GF_FIXTURES_END" {
-				i++
-			}
-		case "// GF_FIXTURES_END":
-			out = append(out, line)
-		default:
-			out = append(out, line)
-		}
+	// Render into fixtures region, keeping its markers
+	var fixtures strings.Builder
+	fixtures.WriteString("// GF_FIXTURES_START\n")
+	// makeCreate<Model>Proto
+	fmt.Fprintf(&fixtures, "func makeCreate%sProto(%s) *proto.%s {\n", capitalizedModelName, strings.Join(createParams, ", "), capitalizedModelName)
+	fixtures.WriteString("\treturn &proto." + capitalizedModelName + "{\n")
+	fixtures.WriteString("\t\tId: \"\",\n\t\tCreated: \"\",\n\t\tUpdated: \"\",\n")
+	for _, f := range createFields {
+		fixtures.WriteString("\t\t" + f + "\n")
 	}
+	fixtures.WriteString("\t}\n}\n\n")
+	// makeEdit<Model>Proto
+	fmt.Fprintf(&fixtures, "func makeEdit%sProto(%s) *proto.%s {\n", capitalizedModelName, strings.Join(editParams, ", "), capitalizedModelName)
+	fixtures.WriteString("\treturn &proto." + capitalizedModelName + "{\n")
+	fixtures.WriteString("\t\tId: id,\n\t\tCreated: \"\",\n\t\tUpdated: \"\",\n")
+	for _, f := range editFields {
+		fixtures.WriteString("\t\t" + f + "\n")
+	}
+	fixtures.WriteString("\t}\n}\n")
+	fixtures.WriteString("// GF_FIXTURES_END\n")
 
-	content := strings.Join(out, "\n")
+	content, err := replaceMarkerRegions(string(contentBytes), map[string]string{"FIXTURES": fixtures.String()})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", templatePath, err)
+	}
 
 	// Go naming conversions
 	goPackageName := toGoPackageName(modelName)
@@ -321,7 +278,7 @@ func generateValidationTestContent(modelName, capitalizedModelName string, colum
 	content = strings.ReplaceAll(content, "Skeleton", capitalizedModelName)
 	content = strings.Replace(content, "package skeleton", "package "+goPackageName, 1)
 	// Fix import path to use goPackageName (lowercase directory) with alias
-	content = strings.Replace(content, `"gofast/service-core/domain/skeleton"`, goVarName+` "gofast/service-core/domain/`+goPackageName+`"`, 1)
+	content = strings.Replace(content, `"`+module+`/`+svc+`/domain/skeleton"`, goVarName+` "`+module+`/`+svc+`/domain/`+goPackageName+`"`, 1)
 	content = strings.ReplaceAll(content, "skeletons", pluralVarName)
 	content = strings.ReplaceAll(content, "skeleton", goVarName)
 
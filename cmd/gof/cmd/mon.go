@@ -3,7 +3,6 @@ package cmd
 import (
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
@@ -19,46 +18,41 @@ var monCmd = &cobra.Command{
 	Use:   "mon",
 	Short: "Add monitoring stack (Grafana, Loki, Tempo, Prometheus)",
 	Args:  cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		email, apiKey, err := auth.CheckAuthentication()
 		if err != nil {
-			cmd.Printf("Authentication failed: %v.\n", err)
-			return
+			return authErr("authentication failed: %v", err)
 		}
 
-		con, err := config.ParseConfig()
+		con, err := config.Open()
 		if err != nil {
-			cmd.Printf("%v\n", err)
-			return
+			return failErr("%v", err)
 		}
+		defer func() { _ = con.Close() }()
 		if con.MonitoringPopulated {
 			cmd.Println("Monitoring files have already been added to this project.")
-			return
+			return nil
+		}
+
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
 		}
 
 		tmpDir, err := os.MkdirTemp("", "gofast-mon-*")
 		if err != nil {
-			cmd.Printf("Error creating temp directory: %v\n", err)
-			return
+			return genErr("creating temp directory: %v", err)
 		}
 		defer func() { _ = os.RemoveAll(tmpDir) }()
 
 		cwd, err := os.Getwd()
 		if err != nil {
-			cmd.Printf("Error getting working directory: %v\n", err)
-			return
-		}
-
-		if err := os.Chdir(tmpDir); err != nil {
-			cmd.Printf("Error changing to temp directory: %v\n", err)
-			return
+			return genErr("getting working directory: %v", err)
 		}
-		defer func() { _ = os.Chdir(cwd) }()
 
 		srcRepoName := "gofast-app-src"
-		if err := repo.DownloadRepo(email, apiKey, srcRepoName); err != nil {
-			cmd.Printf("Error downloading repository to temp directory: %v\n", err)
-			return
+		if _, err := repo.DownloadRepo(email, apiKey, version, filepath.Join(tmpDir, srcRepoName)); err != nil {
+			return downloadErr("downloading repository to temp directory: %v", err)
 		}
 
 		srcRoot := filepath.Join(tmpDir, srcRepoName)
@@ -70,23 +64,19 @@ var monCmd = &cobra.Command{
 			cmd.Printf("File '%s' already exists. Skipping copy.\n", projMonitoringCompose)
 		} else {
 			if err := copyFile(srcMonitoringCompose, projMonitoringCompose); err != nil {
-				cmd.Printf("Error copying %s: %v\n", projMonitoringCompose, err)
-				return
+				return genErr("copying %s: %v", projMonitoringCompose, err)
 			}
 			composeContent, err := os.ReadFile(projMonitoringCompose)
 			if err != nil {
-				cmd.Printf("Error reading %s: %v\n", projMonitoringCompose, err)
-				return
+				return genErr("reading %s: %v", projMonitoringCompose, err)
 			}
-			newComposeContent := strings.ReplaceAll(string(composeContent), "gofast", con.ProjectName)
+			newComposeContent := renameProject(string(composeContent), con.ProjectName)
 			info, err := os.Stat(projMonitoringCompose)
 			if err != nil {
-				cmd.Printf("Error getting file info for %s: %v\n", projMonitoringCompose, err)
-				return
+				return genErr("getting file info for %s: %v", projMonitoringCompose, err)
 			}
 			if err := os.WriteFile(projMonitoringCompose, []byte(newComposeContent), info.Mode()); err != nil {
-				cmd.Printf("Error updating %s: %v\n", projMonitoringCompose, err)
-				return
+				return genErr("updating %s: %v", projMonitoringCompose, err)
 			}
 		}
 
@@ -97,12 +87,10 @@ var monCmd = &cobra.Command{
 			if _, err := os.Stat(dstMonitoringDir); err == nil {
 				cmd.Printf("Directory '%s' already exists. Skipping copy.\n", dstMonitoringDir)
 			} else if err := copyDir(srcMonitoringDir, dstMonitoringDir); err != nil {
-				cmd.Printf("Error copying monitoring directory: %v\n", err)
-				return
+				return genErr("copying monitoring directory: %v", err)
 			}
 		} else {
-			cmd.Printf("Error: monitoring directory not found in template.\n")
-			return
+			return genErr("monitoring directory not found in template")
 		}
 
 		// If infra was already added, copy monitoring.tf into it
@@ -113,22 +101,14 @@ var monCmd = &cobra.Command{
 				cmd.Printf("File '%s' already exists. Skipping copy.\n", dstMonitoringTf)
 			} else {
 				if err := copyFile(srcMonitoringTf, dstMonitoringTf); err != nil {
-					cmd.Printf("Error copying monitoring.tf: %v\n", err)
-					return
+					return genErr("copying monitoring.tf: %v", err)
 				}
 			}
 		}
 
-		err = os.Chdir(cwd)
-		if err != nil {
-			cmd.Printf("Error returning to project directory: %v\n", err)
-			return
-		}
-
-		err = config.MarkMonitoringPopulated()
-		if err != nil {
-			cmd.Printf("Error updating gofast config: %v\n", err)
-			return
+		con.MonitoringPopulated = true
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
 		}
 
 		cmd.Println("")
@@ -144,13 +124,15 @@ var monCmd = &cobra.Command{
 		}
 		cmd.Println("")
 		cmd.Println("Next steps:")
-		cmd.Printf("  Run %s to launch your app with local monitoring stack\n", config.SuccessStyle.Render("'make startm'"))
+		runStep(cmd, 1, "make startm", "to launch your app with local monitoring stack")
 		cmd.Println("")
 		cmd.Println("Access Grafana at http://localhost:3001 (no login required)")
 		cmd.Println("")
 		if !con.InfraPopulated {
+			report.NextSteps = append(report.NextSteps, "gof infra")
 			cmd.Printf("Run %s to add Kubernetes deployment files.\n", config.SuccessStyle.Render("'gof infra'"))
 			cmd.Println("")
 		}
+		return nil
 	},
 }
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// Report is what a command prints with --json instead of its human summary.
+type Report struct {
+	Command       string   `json:"command"`
+	OK            bool     `json:"ok"`
+	ExitCode      int      `json:"exit_code"`
+	FilesCreated  []string `json:"files_created"`
+	FilesModified []string `json:"files_modified"`
+	Migrations    []string `json:"migrations"`
+	Routes        []string `json:"routes"`
+	EnvVars       []string `json:"env_vars"`
+	NextSteps     []string `json:"next_steps"`
+	Warnings      []string `json:"warnings"`
+	Errors        []string `json:"errors"`
+	// Data holds command specific results (doctor report, marker problems, version).
+	Data any `json:"data,omitempty"`
+}
+
+var (
+	jsonOutput bool
+	report     = &Report{}
+	// before is the project tree as it was when the command started; nil
+	// when the command does not run inside a project.
+	before map[string][sha256.Size]byte
+	// stdout is where results go; human progress output goes through the
+	// cobra command and is discarded with --json.
+	stdout io.Writer = os.Stdout
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Print a machine-readable JSON report instead of the human summary")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if jsonOutput || !isTerminal(os.Stdout) || os.Getenv("NO_COLOR") != "" {
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
+		report.Command = cmd.CommandPath()
+		if !jsonOutput {
+			return nil
+		}
+		cmd.Root().SetOut(io.Discard)
+		if _, err := os.Stat(config.ConfigFileName); err == nil {
+			snap, err := snapshotTree(".")
+			if err != nil {
+				return failErr("reading project tree: %w", err)
+			}
+			before = snap
+		}
+		return nil
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// writeReport fills in the file changes and the outcome and prints the
+// report as JSON. Called by Execute whether the command failed or not.
+func writeReport(c *cobra.Command, err error) {
+	if report.Command == "" {
+		report.Command = c.CommandPath()
+	}
+	if before != nil {
+		if after, snapErr := snapshotTree("."); snapErr == nil {
+			report.addChanges(before, after, "")
+		}
+	}
+	report.OK = err == nil
+	if err != nil {
+		report.ExitCode = exitCode(err)
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, m := range append(append([]string{}, report.FilesCreated...), report.FilesModified...) {
+		if strings.Contains(m, "storage/migrations/") && strings.HasSuffix(m, ".sql") && !contains(report.Migrations, m) {
+			report.Migrations = append(report.Migrations, m)
+		}
+	}
+	sort.Strings(report.Migrations)
+	for _, list := range []*[]string{
+		&report.FilesCreated, &report.FilesModified, &report.Migrations, &report.Routes,
+		&report.EnvVars, &report.NextSteps, &report.Warnings, &report.Errors,
+	} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+	data, mErr := json.MarshalIndent(report, "", "  ")
+	if mErr != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", mErr)
+		return
+	}
+	fmt.Fprintln(stdout, string(data))
+}
+
+// addChanges records files that are new or differ between two snapshots.
+// prefix is prepended to every path (used when the snapshot root is a
+// subdirectory of the working directory).
+func (r *Report) addChanges(before, after map[string][sha256.Size]byte, prefix string) {
+	paths := make([]string, 0, len(after))
+	for p := range after {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		old, ok := before[p]
+		switch {
+		case !ok:
+			r.FilesCreated = append(r.FilesCreated, filepath.ToSlash(filepath.Join(prefix, p)))
+		case old != after[p]:
+			r.FilesModified = append(r.FilesModified, filepath.ToSlash(filepath.Join(prefix, p)))
+		}
+	}
+}
+
+// snapshotTree hashes every file below root, skipping the same dependency
+// and build directories the marker linter skips.
+func snapshotTree(root string) (map[string][sha256.Size]byte, error) {
+	snap := map[string][sha256.Size]byte{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && markers.SkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		snap[rel] = sha256.Sum256(data)
+		return nil
+	})
+	return snap, err
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCreatedTree adds every file below dir to the report as created. Used
+// by commands that create a new tree instead of changing the project in the
+// working directory.
+func recordCreatedTree(dir string) {
+	if !jsonOutput {
+		return
+	}
+	snap, err := snapshotTree(dir)
+	if err != nil {
+		return
+	}
+	report.addChanges(nil, snap, dir)
+}
+
+// warnf prints a warning and records it in the report.
+func warnf(cmd *cobra.Command, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	report.Warnings = append(report.Warnings, msg)
+	cmd.Printf("Warning: %s\n", msg)
+}
+
+// runStep prints a numbered "Run 'command' ..." next step and records the
+// command as a follow-up in the report.
+func runStep(cmd *cobra.Command, n int, command, rest string) {
+	report.NextSteps = append(report.NextSteps, command)
+	line := fmt.Sprintf("  %d. Run %s", n, config.SuccessStyle.Render("'"+command+"'"))
+	if rest != "" {
+		line += " " + rest
+	}
+	cmd.Println(line)
+}
+
+// printRoute prints a client route and records it in the report.
+func printRoute(cmd *cobra.Command, route string) {
+	report.Routes = append(report.Routes, route)
+	cmd.Printf("  %s\n", config.SuccessStyle.Render(route))
+}
+
+// printEnvVar prints a required environment variable and records it in the
+// report.
+func printEnvVar(cmd *cobra.Command, name string) {
+	report.EnvVars = append(report.EnvVars, name)
+	cmd.Printf("     - %s\n", name)
+}
@@ -4,9 +4,15 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&config.OfflineFlag, "offline", false, "Skip authentication and copy the template from a local gofast-app checkout (also GOF_OFFLINE=1)")
+	rootCmd.PersistentFlags().StringVar(&config.TemplateFlag, "template", "", "Generate from this template instead of the GoFast server's: a directory, a zip file or a git URL[#ref] (also GOF_TEMPLATE)")
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "gof",
 	Short: "GoFast CLI",
@@ -15,14 +21,27 @@ GoFast CLI is a command line builder for Go related projects.
 Complete documentation is available at https://docs.gofast.live.
 For any issues, suggestions, or help, please visit our Discord server at https://discord.com/invite/EdSZbQbRyJ.
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("Welcome to GoFast! Use 'gof help' for more information.")
+	// Errors are printed once by Execute; usage is only shown for usage errors.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintln(cmd.OutOrStdout(), "Welcome to GoFast! Use 'gof help' for more information.")
+		return nil
 	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	c, err := rootCmd.ExecuteC()
+	if jsonOutput {
+		writeReport(c, err)
+	}
+	if err == nil {
+		return
+	}
+	code := exitCode(err)
+	fmt.Fprintf(os.Stderr, "%s %v\n", config.ErrStyle.Render("Error:"), err)
+	if code == ExitUsage {
+		fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", c.CommandPath())
 	}
+	os.Exit(code)
 }
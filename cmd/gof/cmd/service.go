@@ -0,0 +1,596 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/gomod"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/ports"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceNewCmd)
+	serviceCmd.AddCommand(servicePortCmd)
+}
+
+// coreService is the Go backend every project has.
+const coreService = "core"
+
+// serviceFiles are the compose file and directory of a service.
+func serviceFiles(name string) ports.Files {
+	if name == coreService {
+		return ports.Files{Compose: "docker-compose.yml", Dir: "app/service-core"}
+	}
+	if spec, ok := clients.SpecFor(name); ok {
+		return ports.Files{Compose: spec.ComposeFile, Dir: "app/" + spec.ServiceDir}
+	}
+	return ports.Files{Compose: "docker-compose." + name + ".yml", Dir: "app/service-" + name}
+}
+
+// servicePackage is the directory below app/ of the Go service name.
+func servicePackage(name string) string {
+	return "service-" + name
+}
+
+// serviceProtoDir is the proto package directory of Go service name:
+// proto/v1 for core, proto/<name>/v1 for services added with
+// 'gof service new'.
+func serviceProtoDir(name string) string {
+	if name == coreService {
+		return "proto/v1"
+	}
+	return "proto/" + name + "/v1"
+}
+
+// protoHeader starts a proto file of the package in dir, with its Go stubs
+// generated into app/gen/<dir> (buf's paths=source_relative).
+func protoHeader(module, dir string) string {
+	return "syntax = \"proto3\";\n" +
+		"option go_package = \"" + module + "/gen/" + dir + "\";\n" +
+		"package " + strings.ReplaceAll(dir, "/", ".") + ";\n"
+}
+
+// serviceProtoImports points the proto imports of code generated from a
+// skeleton at the proto package of the service in directory svc.
+func serviceProtoImports(content, module, svc string) string {
+	name := strings.TrimPrefix(svc, "service-")
+	if name == coreService {
+		return content
+	}
+	return strings.ReplaceAll(content, `"`+module+`/gen/proto/v1`, `"`+module+`/gen/`+serviceProtoDir(name))
+}
+
+// checkBackendService reports a usage error unless name is a Go service of
+// cfg that models can be generated into.
+func checkBackendService(cfg *config.Config, name string) error {
+	if _, ok := clients.SpecFor(name); ok {
+		return usageErr("%s is a client; models are generated into Go services", name)
+	}
+	if !cfg.HasService(name) {
+		var names []string
+		for _, s := range cfg.Services {
+			if _, client := clients.SpecFor(s.Name); !client {
+				names = append(names, s.Name)
+			}
+		}
+		return usageErr("no Go service %q in %s (services: %s); create it with 'gof service new %s'",
+			name, config.ConfigFileName, strings.Join(names, ", "), name)
+	}
+	return nil
+}
+
+// defaultPort is the port a service has in the template.
+func defaultPort(name string) string {
+	if name == coreService {
+		return "4000"
+	}
+	if spec, ok := clients.SpecFor(name); ok {
+		return spec.Port
+	}
+	return ""
+}
+
+// usedPorts maps every port taken in the project at root to who takes it:
+// the services in cfg other than except, and the host ports published by
+// the compose files.
+func usedPorts(cfg *config.Config, root, except string) (map[string]string, error) {
+	used := map[string]string{}
+	for _, svc := range cfg.Services {
+		if svc.Name != except {
+			used[svc.Port] = "service " + svc.Name
+		}
+	}
+	bindings, err := ports.Bindings(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bindings {
+		if _, ok := used[b.Port]; !ok {
+			used[b.Port] = fmt.Sprintf("%s:%d", b.File, b.Line)
+		}
+	}
+	return used, nil
+}
+
+// freePort returns preferred, or the next port after it that is not in used.
+func freePort(preferred string, used map[string]string) string {
+	taken := make([]string, 0, len(used))
+	for port := range used {
+		taken = append(taken, port)
+	}
+	return ports.Free(preferred, taken)
+}
+
+// portShared reports whether a service other than name uses port, judged by
+// the ports the services have in the template (template) or in cfg.
+func portShared(cfg *config.Config, name, port string, template bool) bool {
+	for _, svc := range cfg.Services {
+		if svc.Name == name {
+			continue
+		}
+		p := svc.Port
+		if template {
+			p = defaultPort(svc.Name)
+		}
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// renderPorts moves the services of con whose port differs from the
+// template's in the template copy at dir.
+func renderPorts(dir string, con *config.Config) error {
+	for _, svc := range con.Services {
+		from := defaultPort(svc.Name)
+		if from == "" || from == svc.Port {
+			continue
+		}
+		if _, err := ports.Rewrite(dir, serviceFiles(svc.Name), from, svc.Port, portShared(con, svc.Name, from, true)); err != nil {
+			return fmt.Errorf("moving %s to port %s: %w", svc.Name, svc.Port, err)
+		}
+	}
+	return nil
+}
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Manage the project's services",
+}
+
+// validServiceName is a name that works as a compose service, a directory
+// suffix, a Go import path element and a Makefile target.
+var validServiceName = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+var serviceNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Add another Go backend service",
+	Long: `Add another ConnectRPC Go service next to service-core.
+
+The service starts as a copy of the template's service-core (without
+integrations) in app/service-<name>, in the same Go module. It gets its own
+compose file (docker-compose.<name>.yml), the next free port after 4000,
+run-/test-/start- Makefile targets and, when infra was added, its own
+deployment manifests. The database stays owned by service-core: the new
+service imports service-core/storage, so migrations, queries and sqlc
+generation remain in one place. Its RPCs get their own proto package,
+proto/<name>/v1, generated into app/gen/proto/<name>/v1 by 'make gen'.
+
+The copy serves nothing of core's: the login, skeleton and other handlers
+are unwired from its main.go, and their packages removed except the
+skeleton that models are generated from.
+
+Generate models into it with 'gof model <name> ... --service <service>'.
+
+Example:
+  gof service new billing
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if !validServiceName.MatchString(name) {
+			return usageErr("invalid service name %q: use lowercase letters and digits, starting with a letter", name)
+		}
+		if name == coreService {
+			return usageErr("service-core already exists in every project")
+		}
+		if _, ok := clients.SpecFor(name); ok {
+			return usageErr("%s is a client; add it with 'gof client %s'", name, name)
+		}
+
+		email, apiKey, err := auth.CheckAuthentication()
+		if err != nil {
+			return authErr("authentication failed: %v", err)
+		}
+
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+
+		if con.HasService(name) {
+			return failErr("service %s already exists", name)
+		}
+		pkg := servicePackage(name)
+		files := serviceFiles(name)
+		protoDir := serviceProtoDir(name)
+		for _, path := range []string{files.Dir, files.Compose, filepath.Dir(protoDir)} {
+			if _, err := os.Stat(path); err == nil {
+				return failErr("%s already exists; remove it or pick another name", path)
+			}
+		}
+
+		version, err := projectTemplateVersion(cmd, email, apiKey, con.Config)
+		if err != nil {
+			return err
+		}
+
+		tmpDir, err := os.MkdirTemp("", "gofast-service-*")
+		if err != nil {
+			return genErr("creating temp directory: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		srcRoot := filepath.Join(tmpDir, "gofast-app-src")
+		if _, err := repo.DownloadRepo(email, apiKey, version, srcRoot); err != nil {
+			return downloadErr("downloading repository to temp directory: %v", err)
+		}
+		// A new service starts without integrations: they stay in core.
+		template := *con.Config
+		template.Integrations = nil
+		if err := renderTemplate(srcRoot, &template); err != nil {
+			return genErr("preparing template: %v", err)
+		}
+
+		used, err := usedPorts(con.Config, ".", name)
+		if err != nil {
+			return failErr("reading compose files: %v", err)
+		}
+		port := freePort(defaultPort(coreService), used)
+		corePort := defaultPort(coreService)
+		for _, svc := range con.Services {
+			if svc.Name == coreService {
+				corePort = svc.Port
+			}
+		}
+
+		rollback := func() {
+			_ = os.RemoveAll(files.Dir)
+			_ = os.Remove(files.Compose)
+			_ = os.RemoveAll(filepath.Dir(protoDir))
+		}
+		if err := copyServiceCore(srcRoot, con.GoModule(), name); err != nil {
+			rollback()
+			return genErr("creating %s: %v", files.Dir, err)
+		}
+		if err := writeServiceProto(con.GoModule(), name); err != nil {
+			rollback()
+			return genErr("creating %s: %v", protoDir, err)
+		}
+		// The copy publishes and listens on core's port until it is moved.
+		if err := writeServiceCompose(name); err != nil {
+			rollback()
+			return genErr("creating %s: %v", files.Compose, err)
+		}
+		if _, err := ports.Rewrite(".", files, corePort, port, true); err != nil {
+			rollback()
+			return genErr("moving %s to port %s: %v", name, port, err)
+		}
+		if con.InfraPopulated {
+			manifests, err := copyServiceInfra(srcRoot, name)
+			for _, path := range manifests {
+				if err == nil {
+					_, err = ports.Rewrite(".", ports.Files{Compose: path}, corePort, port, true)
+				}
+			}
+			if err != nil {
+				for _, path := range manifests {
+					_ = os.Remove(path)
+				}
+				rollback()
+				return genErr("adding infra manifests: %v", err)
+			}
+		} else {
+			warnf(cmd, "no infra manifests for %s: run 'gof infra' first, then copy infra/service-core.tf", pkg)
+		}
+		if err := appendServiceTargets(name); err != nil {
+			warnf(cmd, "could not add Makefile targets: %v", err)
+		}
+
+		con.AddService(name, port)
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
+		}
+
+		cmd.Println("")
+		cmd.Println(config.SuccessStyle.Render(fmt.Sprintf("Service %s added on port %s!", name, port)))
+		cmd.Println("")
+		cmd.Printf("  - Code:    %s\n", config.SuccessStyle.Render(files.Dir))
+		cmd.Printf("  - Compose: %s\n", config.SuccessStyle.Render(files.Compose))
+		cmd.Printf("  - Proto:   %s\n", config.SuccessStyle.Render(protoDir+"/main.proto"))
+		cmd.Println("")
+		cmd.Println("Next steps:")
+		runStep(cmd, 1, "gof model <model> <column:type>... --service "+name, "to generate models into it")
+		runStep(cmd, 2, "make start-"+name, "to launch it next to service-core")
+		cmd.Println("")
+		return nil
+	},
+}
+
+var servicePortCmd = &cobra.Command{
+	Use:   "port <name> <port>",
+	Short: "Move a service to another port",
+	Long: `Move a service to another port.
+
+The service's compose file and directory are rewritten: port mappings, PORT
+settings, --port flags, EXPOSE lines and URLs. In the rest of the project
+(other compose files, .env files, client transport URLs) URLs pointing at
+the old port are moved too, unless another service also uses that port.
+gofast.json records the new port.
+
+Example:
+  gof service port tanstack 3001
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, port := args[0], args[1]
+		if err := ports.Check(port); err != nil {
+			return usageErr("%v", err)
+		}
+
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+
+		var svc *config.Service
+		for i := range con.Services {
+			if con.Services[i].Name == name {
+				svc = &con.Services[i]
+			}
+		}
+		if svc == nil {
+			var names []string
+			for _, s := range con.Services {
+				names = append(names, s.Name)
+			}
+			return usageErr("no service %q in %s (services: %s)", name, config.ConfigFileName, strings.Join(names, ", "))
+		}
+		from := svc.Port
+		if from == port {
+			cmd.Printf("%s already uses port %s.\n", name, port)
+			return nil
+		}
+
+		used, err := usedPorts(con.Config, ".", name)
+		if err != nil {
+			return failErr("reading compose files: %v", err)
+		}
+		delete(used, from)
+		if owner, ok := used[port]; ok {
+			return usageErr("port %s is already used by %s; try %s", port, owner, freePort(port, used))
+		}
+
+		shared := portShared(con.Config, name, from, false)
+		changes, err := ports.Rewrite(".", serviceFiles(name), from, port, shared)
+		if err != nil {
+			return genErr("moving %s to port %s: %v", name, port, err)
+		}
+		svc.Port = port
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %v", config.ConfigFileName, err)
+		}
+		report.Data = map[string]any{"service": name, "from": from, "to": port, "changes": changes}
+
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+		cmd.Println("")
+		for _, c := range changes {
+			cmd.Printf("  %s (%d)\n", c.Path, c.Count)
+		}
+		compose := serviceFiles(name).Compose
+		if !containsChange(changes, compose) {
+			warnf(cmd, "%s had no reference to port %s; check that %s publishes %s", compose, from, name, port)
+		}
+		if shared {
+			warnf(cmd, "another service also uses port %s, so URLs to it outside %s were left alone; check them by hand", from, serviceFiles(name).Dir)
+		}
+		cmd.Println("")
+		cmd.Println(config.SuccessStyle.Render(fmt.Sprintf("%s moved from port %s to %s.", name, from, port)))
+		cmd.Println("")
+		return nil
+	},
+}
+
+func containsChange(changes []ports.Change, path string) bool {
+	for _, c := range changes {
+		if c.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// copyServiceCore copies service-core of the rendered template at srcRoot
+// into app/service-<name>. Its storage package is left out: the copy keeps
+// importing service-core's, so both services share one database schema.
+// Core's handlers are unwired from the copy's main.go and their packages
+// removed.
+func copyServiceCore(srcRoot, module, name string) error {
+	pkg := servicePackage(name)
+	src := filepath.Join(srcRoot, "app", "service-core")
+	dst := filepath.Join("app", pkg)
+	if err := copyDir(src, dst); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(dst, "storage")); err != nil {
+		return err
+	}
+	if err := gomod.Rewrite(dst, module+"/service-core", module+"/"+pkg); err != nil {
+		return err
+	}
+	if err := gomod.Rewrite(dst, module+"/"+pkg+"/storage", module+"/service-core/storage"); err != nil {
+		return err
+	}
+	if err := stripServiceMain(filepath.Join(dst, "main.go"), module+"/"+pkg); err != nil {
+		return fmt.Errorf("unwiring core's handlers: %w", err)
+	}
+	if err := pruneServicePackages(dst, module+"/"+pkg); err != nil {
+		return fmt.Errorf("removing core's handlers: %w", err)
+	}
+	// Dockerfiles, env files and the like name the directory literally.
+	return filepath.WalkDir(dst, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) == ".go" {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		renamed := serviceCoreDir.ReplaceAllStringFunc(string(content), func(m string) string {
+			if m == "service-core" {
+				return pkg
+			}
+			return m
+		})
+		if renamed == string(content) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, []byte(renamed), info.Mode().Perm())
+	})
+}
+
+// writeServiceProto creates proto/<name>/v1/main.proto, the package 'gof
+// model --service <name>' adds the model services to.
+func writeServiceProto(module, name string) error {
+	dir := serviceProtoDir(name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	content := protoHeader(module, dir) + "\n// Services of " + servicePackage(name) + ", one per model.\n"
+	return os.WriteFile(filepath.Join(dir, "main.proto"), []byte(content), 0o644)
+}
+
+// serviceCoreDir matches the service-core directory and, to keep it, its
+// storage package.
+var serviceCoreDir = regexp.MustCompile(`service-core(?:/storage)?`)
+
+// coreName matches "core" as a word: the compose service, its container
+// and the service-core directory.
+var coreName = regexp.MustCompile(`\bcore\b`)
+
+// writeServiceCompose writes docker-compose.<name>.yml with a copy of the
+// core service of the project's docker-compose.yml, renamed to name. It
+// still publishes core's port; the caller moves it.
+func writeServiceCompose(name string) error {
+	content, err := os.ReadFile("docker-compose.yml")
+	if err != nil {
+		return err
+	}
+	block, ok := composeServiceBlock(string(content), coreService)
+	if !ok {
+		return fmt.Errorf("no %s service in docker-compose.yml", coreService)
+	}
+	block = coreName.ReplaceAllString(block, name)
+	return os.WriteFile(serviceFiles(name).Compose, []byte("services:\n"+block), 0o644)
+}
+
+// composeServiceBlock returns the lines of service name in a compose file:
+// its "name:" key below services and everything indented deeper.
+func composeServiceBlock(content, name string) (string, bool) {
+	lines := strings.SplitAfter(content, "\n")
+	start, indent := -1, 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != name+":" {
+			continue
+		}
+		indent = len(line) - len(strings.TrimLeft(line, " "))
+		if indent > 0 {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+	end := start + 1
+	for ; end < len(lines); end++ {
+		line := lines[end]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(line)-len(strings.TrimLeft(line, " ")) <= indent {
+			break
+		}
+	}
+	// Leave blank lines between services out.
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return strings.Join(lines[start:end], ""), true
+}
+
+// appendServiceTargets adds run-, test- and start- targets for service name
+// to the project Makefile.
+func appendServiceTargets(name string) error {
+	if _, err := os.Stat("Makefile"); err != nil {
+		return err
+	}
+	pkg := servicePackage(name)
+	compose := serviceFiles(name).Compose
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n.PHONY: run-%s test-%s start-%s\n", name, name, name)
+	fmt.Fprintf(&b, "run-%s:\n\tcd app && go run ./%s\n\n", name, pkg)
+	fmt.Fprintf(&b, "test-%s:\n\tcd app && go test -race ./%s/...\n\n", name, pkg)
+	fmt.Fprintf(&b, "start-%s:\n\tdocker compose -f docker-compose.yml -f %s up --build\n", name, compose)
+	return appendToFile("Makefile", b.String())
+}
+
+// copyServiceInfra adds the deployment manifests of service name: a copy of
+// every file of the rendered template's infra directory that is about
+// service-core, renamed. It returns the files it wrote; they still use
+// core's port.
+func copyServiceInfra(srcRoot, name string) ([]string, error) {
+	src := filepath.Join(srcRoot, "infra")
+	var written []string
+	err := filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.Contains(d.Name(), "service-core") {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join("infra", strings.ReplaceAll(rel, "service-core", servicePackage(name)))
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, []byte(coreName.ReplaceAllString(string(content), name)), 0o644); err != nil {
+			return err
+		}
+		written = append(written, filepath.ToSlash(dst))
+		return nil
+	})
+	return written, err
+}
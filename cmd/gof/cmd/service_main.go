@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/goedit"
+)
+
+// stripServiceMain removes from main.go of a new service everything that
+// serves the copied domain and transport packages of service-core: their
+// imports, and every statement of func main that uses them or a variable
+// set by a removed statement (deps, servers, handler mounts). Imports only
+// the removed code used are dropped, and so are var declarations it was the
+// only reader of (path, handler). Other locals it was the only reader of,
+// like the store, get a "_ = x" so main still compiles and 'gof model
+// --service' can wire models to them. pkgPath is the import path
+// of the service, e.g. "<module>/service-billing".
+func stripServiceMain(mainPath, pkgPath string) error {
+	f, err := goedit.ParseFile(mainPath)
+	if err != nil {
+		return err
+	}
+	file := f.Syntax()
+	body, err := f.FuncScope("main")
+	if err != nil {
+		return err
+	}
+
+	dead := map[string]bool{}
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || !strings.HasPrefix(p, pkgPath+"/domain/") && !strings.HasPrefix(p, pkgPath+"/transport/") {
+			continue
+		}
+		dead[importName(imp, p)] = true
+		f.Remove(imp)
+	}
+	if len(dead) == 0 {
+		return nil
+	}
+
+	// Walk main in order: a statement using a dead name goes, and the names
+	// it sets are dead until a kept statement sets them again.
+	removedRefs := map[string]bool{}
+	var kept []ast.Stmt
+	for _, n := range body.Nodes {
+		stmt := n.(ast.Stmt)
+		refs := map[string]bool{}
+		identRefs(stmt, refs)
+		if usesAny(refs, dead) {
+			f.Remove(stmt)
+			for name := range refs {
+				removedRefs[name] = true
+			}
+			for _, name := range assignedNames(stmt) {
+				dead[name] = true
+			}
+			continue
+		}
+		for _, name := range assignedNames(stmt) {
+			delete(dead, name)
+		}
+		kept = append(kept, stmt)
+	}
+
+	remaining := map[string]bool{}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			continue
+		}
+		identRefs(decl, remaining)
+	}
+	reads := map[string]bool{}
+	for _, stmt := range kept {
+		identRefs(stmt, remaining)
+		readRefs(stmt, reads)
+	}
+
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(imp, p)
+		if removedRefs[name] && !remaining[name] && !dead[name] {
+			f.Remove(imp)
+		}
+	}
+	for _, stmt := range kept {
+		if !declares(stmt) {
+			continue
+		}
+		if isBareVar(stmt) && !usesAny(reads, setOf(assignedNames(stmt))) {
+			f.Remove(stmt)
+			continue
+		}
+		for _, name := range assignedNames(stmt) {
+			if removedRefs[name] && !reads[name] {
+				if err := f.Insert(body, "_ = "+name, "use of "+name, goedit.AfterNode(stmt)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return f.WriteFile(mainPath)
+}
+
+// majorVersion matches the /vN suffix of a module path.
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+// importName is the name an import is used by in the file: its alias, or
+// the last element of its path that is not a major version.
+func importName(imp *ast.ImportSpec, p string) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	name := path.Base(p)
+	if majorVersion.MatchString(name) && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	return name
+}
+
+// identRefs adds the identifiers n refers to: no selected field names and no
+// struct literal keys.
+func identRefs(n ast.Node, refs map[string]bool) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			identRefs(n.X, refs)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); !ok {
+				identRefs(n.Key, refs)
+			}
+			identRefs(n.Value, refs)
+			return false
+		case *ast.Ident:
+			refs[n.Name] = true
+		}
+		return true
+	})
+}
+
+// readRefs adds the identifiers stmt reads: those of identRefs except the
+// plain names it assigns to.
+func readRefs(stmt ast.Stmt, refs map[string]bool) {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range s.Lhs {
+			if _, ok := lhs.(*ast.Ident); !ok {
+				identRefs(lhs, refs)
+			}
+		}
+		for _, rhs := range s.Rhs {
+			identRefs(rhs, refs)
+		}
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			identRefs(s, refs)
+			return
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				identRefs(spec, refs)
+				continue
+			}
+			if vs.Type != nil {
+				identRefs(vs.Type, refs)
+			}
+			for _, v := range vs.Values {
+				identRefs(v, refs)
+			}
+		}
+	default:
+		identRefs(stmt, refs)
+	}
+}
+
+// assignedNames are the local names stmt declares or assigns.
+func assignedNames(stmt ast.Stmt) []string {
+	var names []string
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range s.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" {
+				names = append(names, id.Name)
+			}
+		}
+	case *ast.DeclStmt:
+		if gen, ok := s.Decl.(*ast.GenDecl); ok && gen.Tok == token.VAR {
+			for _, spec := range gen.Specs {
+				for _, id := range spec.(*ast.ValueSpec).Names {
+					if id.Name != "_" {
+						names = append(names, id.Name)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// declares reports whether stmt declares variables (":=" or var).
+func declares(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		return s.Tok == token.DEFINE
+	case *ast.DeclStmt:
+		gen, ok := s.Decl.(*ast.GenDecl)
+		return ok && gen.Tok == token.VAR
+	}
+	return false
+}
+
+// isBareVar reports whether stmt is a var declaration without values.
+func isBareVar(stmt ast.Stmt) bool {
+	decl, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return false
+	}
+	gen, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR {
+		return false
+	}
+	for _, spec := range gen.Specs {
+		if len(spec.(*ast.ValueSpec).Values) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func setOf(names []string) map[string]bool {
+	set := map[string]bool{}
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+func usesAny(refs, names map[string]bool) bool {
+	for name := range refs {
+		if names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneServicePackages removes the domain and transport packages copied
+// from service-core into the service at dir, except the skeleton that
+// 'gof model --service' generates from and the packages the remaining code
+// still imports. pkgPath is the service's import path.
+func pruneServicePackages(dir, pkgPath string) error {
+	candidates := map[string]bool{}
+	for _, layer := range []string{"domain", "transport"} {
+		entries, err := os.ReadDir(filepath.Join(dir, layer))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() && e.Name() != "skeleton" {
+				candidates[layer+"/"+e.Name()] = true
+			}
+		}
+	}
+	// Keep a candidate once a file outside the candidates imports it, until
+	// no more are kept.
+	for changed := true; changed; {
+		changed = false
+		err := filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if candidates[filepath.ToSlash(rel)] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(p) != ".go" {
+				return nil
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly)
+			if err != nil {
+				return err
+			}
+			for _, imp := range file.Imports {
+				ip, err := strconv.Unquote(imp.Path.Value)
+				if err != nil {
+					continue
+				}
+				if rel := strings.TrimPrefix(ip, pkgPath+"/"); rel != ip && candidates[rel] {
+					delete(candidates, rel)
+					changed = true
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for rel := range candidates {
+		if err := os.RemoveAll(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
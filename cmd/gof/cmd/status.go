@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/integrations"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
+
+const authGoPath = "app/pkg/auth/auth.go"
+
+type statusService struct {
+	Name   string `json:"name"`
+	Port   string `json:"port"`
+	Client bool   `json:"client"`
+}
+
+type permissionBit struct {
+	Bit  int    `json:"bit"`
+	Name string `json:"name"`
+}
+
+type projectStatus struct {
+	ProjectName     string          `json:"project_name"`
+	Module          string          `json:"module"`
+	TemplateVersion string          `json:"template_version"`
+	CLIVersion      string          `json:"cli_version"`
+	Models          []config.Model  `json:"models"`
+	Services        []statusService `json:"services"`
+	Integrations    []string        `json:"integrations"`
+	Infra           bool            `json:"infra"`
+	Monitoring      bool            `json:"monitoring"`
+	LatestMigration int             `json:"latest_migration"`
+	Permissions     []permissionBit `json:"permissions"`
+	UserAccess      *int64          `json:"user_access,omitempty"`
+	Problems        []string        `json:"-"` // reported as warnings
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show an overview of the project",
+	Long: `Summarize the project from gofast.json and the tree: models with their
+columns, services and ports, integrations, infra and monitoring, the gof
+version that last wrote gofast.json, the latest migration number and the
+permission bit layout of app/pkg/auth/auth.go.
+
+Use --json for the same overview as a machine-readable report.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.ParseConfig()
+		if err != nil {
+			return failErr("%v", err)
+		}
+
+		status := collectStatus(cfg)
+		report.Data = status
+		printStatus(cmd, status)
+		return nil
+	},
+}
+
+func collectStatus(cfg *config.Config) projectStatus {
+	s := projectStatus{
+		ProjectName:     cfg.ProjectName,
+		Module:          cfg.GoModule(),
+		TemplateVersion: cfg.TemplateVersion,
+		CLIVersion:      cfg.CLIVersion,
+		Models:          cfg.Models,
+		Integrations:    cfg.Integrations,
+		Infra:           cfg.InfraPopulated,
+		Monitoring:      cfg.MonitoringPopulated,
+		Permissions:     []permissionBit{},
+	}
+	for _, svc := range cfg.Services {
+		_, client := clients.SpecFor(svc.Name)
+		s.Services = append(s.Services, statusService{Name: svc.Name, Port: svc.Port, Client: client})
+	}
+
+	if next, err := integrations.GetNextMigrationNumber(); err != nil {
+		s.Problems = append(s.Problems, "reading migrations: "+err.Error())
+	} else {
+		s.LatestMigration = next - 1
+	}
+
+	perms, access, err := permissionLayout(authGoPath)
+	if err != nil {
+		s.Problems = append(s.Problems, err.Error())
+	} else {
+		s.Permissions = perms
+		s.UserAccess = access
+	}
+	return s
+}
+
+// permissionLayout reads the single-bit permission flags declared with iota
+// in auth.go, ordered by bit, and the value of UserAccess if it is a
+// constant. The file is type-checked on its own, so only constants that do
+// not depend on other packages are evaluated - which the flags never do.
+func permissionLayout(path string) ([]permissionBit, *int64, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading permissions: %w", err)
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	conf := types.Config{
+		Importer: noImporter{},
+		Error:    func(error) {},
+	}
+	_, _ = conf.Check(file.Name.Name, fset, []*ast.File{file}, info)
+
+	var perms []permissionBit
+	var access *int64
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		flags := usesIota(gen)
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				c, ok := info.Defs[name].(*types.Const)
+				if !ok || c.Val().Kind() != constant.Int {
+					continue
+				}
+				v, exact := constant.Uint64Val(c.Val())
+				if !exact {
+					continue
+				}
+				if name.Name == "UserAccess" {
+					n := int64(v)
+					access = &n
+					continue
+				}
+				if flags && bits.OnesCount64(v) == 1 {
+					perms = append(perms, permissionBit{Bit: bits.TrailingZeros64(v), Name: name.Name})
+				}
+			}
+		}
+	}
+	if len(perms) == 0 {
+		return nil, nil, fmt.Errorf("no permission flags found in %s", path)
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i].Bit < perms[j].Bit })
+	return perms, access, nil
+}
+
+// usesIota reports whether a const declaration is an iota group.
+func usesIota(decl *ast.GenDecl) bool {
+	found := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "iota" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// noImporter lets auth.go be type-checked without its dependencies.
+type noImporter struct{}
+
+func (noImporter) Import(path string) (*types.Package, error) {
+	return nil, errors.New("not loaded")
+}
+
+func printStatus(cmd *cobra.Command, s projectStatus) {
+	cmd.Println("")
+	cmd.Printf("%s (module %s)\n", config.SuccessStyle.Render(s.ProjectName), s.Module)
+	template := s.TemplateVersion
+	if template == "" {
+		template = "not pinned"
+	}
+	cli := s.CLIVersion
+	if cli == "" {
+		cli = "unknown"
+	}
+	cmd.Printf("  template %s, last written by gof %s\n", template, cli)
+
+	cmd.Println("")
+	cmd.Println("Models")
+	for _, m := range s.Models {
+		columns := make([]string, len(m.Columns))
+		for i, c := range m.Columns {
+			columns[i] = c.Name + " " + config.ActiveStyle.Render(c.Type)
+		}
+		service := ""
+		if m.Service != "" {
+			service = " (" + servicePackage(m.Service) + ")"
+		}
+		cmd.Printf("  %s%s: %s\n", m.Name, service, strings.Join(columns, ", "))
+	}
+
+	cmd.Println("")
+	cmd.Println("Services")
+	for _, svc := range s.Services {
+		kind := "service"
+		if svc.Client {
+			kind = "client"
+		}
+		cmd.Printf("  %-10s %-7s port %s\n", svc.Name, kind, svc.Port)
+	}
+
+	cmd.Println("")
+	cmd.Println("Integrations")
+	if len(s.Integrations) == 0 {
+		cmd.Println("  none (gof add stripe|s3|postmark)")
+	} else {
+		cmd.Printf("  %s\n", strings.Join(s.Integrations, ", "))
+	}
+
+	cmd.Println("")
+	cmd.Println("Deployment")
+	cmd.Printf("  infra:      %s\n", statusAdded(s.Infra, "gof infra"))
+	cmd.Printf("  monitoring: %s\n", statusAdded(s.Monitoring, "gof mon"))
+
+	cmd.Println("")
+	cmd.Println("Migrations")
+	cmd.Printf("  latest: %05d\n", s.LatestMigration)
+
+	cmd.Println("")
+	cmd.Println("Permissions (" + authGoPath + ")")
+	for _, p := range s.Permissions {
+		cmd.Printf("  bit %2d  %s\n", p.Bit, p.Name)
+	}
+	if s.UserAccess != nil {
+		cmd.Printf("  UserAccess = %d\n", *s.UserAccess)
+	}
+
+	for _, p := range s.Problems {
+		warnf(cmd, "%s", p)
+	}
+	cmd.Println("")
+}
+
+func statusAdded(added bool, command string) string {
+	if added {
+		return config.SuccessStyle.Render("added")
+	}
+	return "not added (" + command + ")"
+}
@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/auth"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/integrations"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/merge"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().String("from", "", "Template version the project was created from (required when gofast.json has no template_version)")
+	upgradeCmd.Flags().String("to", "", "Template version to upgrade to (default: the latest)")
+	upgradeCmd.Flags().Bool("dry-run", false, "Show what would change without writing any file")
+	upgradeCmd.Flags().Bool("force", false, "Upgrade even when the git working tree has uncommitted changes")
+}
+
+const migrationsDir = "app/service-core/storage/migrations/"
+
+// Upgrade actions, in the order they are printed.
+const (
+	upgradeUpdate   = "update"   // template changed, project file untouched: take the new file
+	upgradeMerge    = "merge"    // both changed: three-way merged cleanly
+	upgradeConflict = "conflict" // both changed the same lines: written with conflict markers
+	upgradeAdd      = "add"      // new template file
+	upgradeDelete   = "delete"   // removed from the template, project file untouched
+	upgradeKeep     = "keep"     // template change not applied; see the reason
+)
+
+// upgradeChange is what gof upgrade does to one project file.
+type upgradeChange struct {
+	Path    string `json:"path"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason,omitempty"`
+	content []byte
+	mode    fs.FileMode
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Merge template updates into the project",
+	Long: `Merge the changes between the template version the project was created from
+(template_version in gofast.json) and a newer one into the project.
+
+Both template versions are shaped like the project first (same clients,
+integrations, infra and monitoring), then every file is three-way merged:
+files you did not touch are updated, your edits are kept, and lines changed
+on both sides are marked with <<<<<<< / ||||||| / ======= / >>>>>>> for you
+to resolve. Generated model code is left alone; applied migrations are never
+rewritten, new ones are added with the next free number. template_version is
+updated afterwards.
+
+Projects created before template versions were recorded need --from.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, apiKey, err := auth.CheckAuthentication()
+		if err != nil {
+			return authErr("authentication failed: %v", err)
+		}
+		con, err := config.Open()
+		if err != nil {
+			return failErr("%v", err)
+		}
+		defer func() { _ = con.Close() }()
+		if config.Offline() || config.TemplateSource() != "" {
+			return usageErr("gof upgrade compares two server template versions and cannot run with --offline or --template")
+		}
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		if from == "" {
+			from = con.TemplateVersion
+		}
+		if from == "" {
+			return usageErr("%s has no template_version; pass --from <version> with the template version this project was created from", config.ConfigFileName)
+		}
+		if !repo.ServerVersion(from) {
+			return usageErr("template %s was not served by the GoFast server; pass --from <version> with the server template version this project matches", from)
+		}
+		if !dryRun && !force {
+			if dirty, err := gitDirty(); err != nil {
+				warnf(cmd, "could not check git status: %v", err)
+			} else if dirty {
+				return failErr("the working tree has uncommitted changes; commit or stash them first, or pass --force")
+			}
+		}
+
+		tmpDir, err := os.MkdirTemp("", "gofast-upgrade-*")
+		if err != nil {
+			return genErr("creating temp directory: %v", err)
+		}
+		defer func() { _ = os.RemoveAll(tmpDir) }()
+
+		newDir := filepath.Join(tmpDir, "new")
+		newVersion, err := repo.DownloadRepo(email, apiKey, to, newDir)
+		if err != nil {
+			return downloadErr("downloading template: %w", err)
+		}
+		report.Data = map[string]any{"from": from, "to": newVersion}
+		if newVersion == from {
+			if con.TemplateVersion == "" && !dryRun {
+				con.TemplateVersion = from
+				if err := con.Save(); err != nil {
+					return failErr("updating %s: %w", config.ConfigFileName, err)
+				}
+			}
+			cmd.Printf("Already on template %s.\n", from)
+			return nil
+		}
+		oldDir := filepath.Join(tmpDir, "old")
+		if _, err := repo.DownloadRepo(email, apiKey, from, oldDir); err != nil {
+			return downloadErr("downloading template %s: %w", from, err)
+		}
+
+		for _, dir := range []string{oldDir, newDir} {
+			if err := renderTemplate(dir, con.Config); err != nil {
+				return genErr("preparing template: %v", err)
+			}
+		}
+		changes, err := planUpgrade(oldDir, newDir, merge.Labels{
+			Ours:   "project",
+			Base:   "template " + from,
+			Theirs: "template " + newVersion,
+		})
+		if err != nil {
+			return genErr("comparing templates: %v", err)
+		}
+		report.Data = map[string]any{"from": from, "to": newVersion, "changes": changes}
+
+		cmd.Printf("Upgrading template %s -> %s\n", from, newVersion)
+		if len(changes) == 0 {
+			cmd.Println("No template changes apply to this project.")
+		}
+		conflicts := 0
+		for _, c := range changes {
+			line := fmt.Sprintf("  %-8s %s", c.Action, c.Path)
+			if c.Reason != "" {
+				line += " (" + c.Reason + ")"
+			}
+			cmd.Println(line)
+			if c.Action == upgradeConflict {
+				conflicts++
+			}
+		}
+		if skeletonChanged(changes) {
+			var models []string
+			for _, m := range con.Models {
+				if m.Name != "skeleton" {
+					models = append(models, m.Name)
+				}
+			}
+			if len(models) > 0 {
+				warnf(cmd, "the model skeleton changed; code generated earlier for %s keeps the old version", strings.Join(models, ", "))
+			}
+		}
+		if dryRun {
+			cmd.Println("")
+			cmd.Println("Dry run: no files were changed.")
+			return nil
+		}
+
+		if err := applyUpgrade(changes); err != nil {
+			return genErr("applying upgrade: %v", err)
+		}
+		con.TemplateVersion = newVersion
+		if err := con.Save(); err != nil {
+			return failErr("updating %s: %w", config.ConfigFileName, err)
+		}
+
+		cmd.Println("")
+		if conflicts > 0 {
+			cmd.Println("Next steps:")
+			cmd.Println("  1. Resolve the conflict markers in the files listed as 'conflict'")
+			runStep(cmd, 2, "make sql", "to regenerate SQL queries")
+			runStep(cmd, 3, "make gen", "to regenerate proto code")
+			cmd.Println("")
+			return failErr("%d file(s) have conflicts; template_version is now %s", conflicts, newVersion)
+		}
+		cmd.Println(config.SuccessStyle.Render("Upgraded to template " + newVersion + "."))
+		cmd.Println("")
+		cmd.Println("Next steps:")
+		runStep(cmd, 1, "make sql", "to regenerate SQL queries")
+		runStep(cmd, 2, "make gen", "to regenerate proto code")
+		runStep(cmd, 3, "git diff", "to review the changes")
+		cmd.Println("")
+		return nil
+	},
+}
+
+// gitDirty reports whether the project's git working tree has uncommitted
+// changes. A project that is not a git repository is never dirty.
+func gitDirty() (bool, error) {
+	if _, err := os.Stat(".git"); os.IsNotExist(err) {
+		return false, nil
+	}
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	if err != nil {
+		return false, err
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
+
+// planUpgrade compares every file of the rendered old and new templates with
+// the project in the working directory and decides what to do with it.
+func planUpgrade(oldDir string, newDir string, labels merge.Labels) ([]upgradeChange, error) {
+	paths := map[string]bool{}
+	for _, dir := range []string{oldDir, newDir} {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if markers.SkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			paths[filepath.ToSlash(rel)] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var changes []upgradeChange
+	for _, p := range sorted {
+		c, err := planFile(p, oldDir, newDir, labels)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			changes = append(changes, *c)
+		}
+	}
+	return changes, nil
+}
+
+// planFile decides what happens to one path. It returns nil when there is
+// nothing to do.
+func planFile(p string, oldDir string, newDir string, labels merge.Labels) (*upgradeChange, error) {
+	base, baseOK, err := readOptional(filepath.Join(oldDir, p))
+	if err != nil {
+		return nil, err
+	}
+	theirs, theirsOK, err := readOptional(filepath.Join(newDir, p))
+	if err != nil {
+		return nil, err
+	}
+	ours, oursOK, err := readOptional(p)
+	if err != nil {
+		return nil, err
+	}
+	if baseOK && theirsOK && bytes.Equal(base, theirs) {
+		return nil, nil // the template did not change this file
+	}
+	c := &upgradeChange{Path: p, mode: 0o644}
+	if info, err := os.Stat(filepath.Join(newDir, p)); err == nil {
+		c.mode = info.Mode().Perm()
+	}
+	if info, err := os.Stat(p); err == nil {
+		c.mode = info.Mode().Perm()
+	}
+	migration := strings.HasPrefix(p, migrationsDir)
+	generated := markers.Generated(theirs) || markers.Generated(ours)
+
+	switch {
+	case !theirsOK: // removed from the template
+		if !oursOK {
+			return nil, nil
+		}
+		if !bytes.Equal(ours, base) {
+			c.Action, c.Reason = upgradeKeep, "removed from the template but changed in the project"
+			return c, nil
+		}
+		c.Action = upgradeDelete
+		return c, nil
+
+	case !oursOK && baseOK: // the project removed or renamed it
+		if migration {
+			c.Action, c.Reason = upgradeKeep, "migration changed in the template; not in the project under this name"
+			return c, nil
+		}
+		c.Action, c.Reason = upgradeKeep, "removed from the project"
+		return c, nil
+
+	case !oursOK: // new in the template
+		if migration {
+			c.Action, c.Reason, c.content = upgradeAdd, "renumbered after the project's migrations", theirs
+			return c, nil
+		}
+		if parent := filepath.Dir(p); parent != "." {
+			if _, err := os.Stat(parent); err != nil {
+				return nil, nil // belongs to a part of the template the project does not use
+			}
+		}
+		c.Action, c.content = upgradeAdd, theirs
+		return c, nil
+
+	case bytes.Equal(ours, theirs):
+		return nil, nil
+
+	case migration:
+		c.Action, c.Reason = upgradeKeep, "migrations that may have been applied are never rewritten"
+		return c, nil
+
+	case generated:
+		c.Action, c.Reason = upgradeKeep, "generated file; regenerate it"
+		return c, nil
+
+	case baseOK && bytes.Equal(ours, base):
+		c.Action, c.content = upgradeUpdate, theirs
+		return c, nil
+
+	case merge.IsBinary(ours) || merge.IsBinary(theirs) || merge.IsBinary(base):
+		c.Action, c.Reason = upgradeKeep, "binary file changed in both the project and the template"
+		return c, nil
+	}
+
+	merged, conflicts := merge.Text(string(base), string(ours), string(theirs), labels)
+	c.content = []byte(merged)
+	c.Action = upgradeMerge
+	if conflicts > 0 {
+		c.Action, c.Reason = upgradeConflict, fmt.Sprintf("%d conflict(s)", conflicts)
+	}
+	return c, nil
+}
+
+func readOptional(path string) ([]byte, bool, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return content, true, nil
+}
+
+// applyUpgrade writes the planned changes to the project.
+func applyUpgrade(changes []upgradeChange) error {
+	for _, c := range changes {
+		switch c.Action {
+		case upgradeUpdate, upgradeMerge, upgradeConflict:
+			if err := os.WriteFile(c.Path, c.content, c.mode); err != nil {
+				return err
+			}
+		case upgradeAdd:
+			path := c.Path
+			if strings.HasPrefix(path, migrationsDir) {
+				next, err := integrations.GetNextMigrationNumber()
+				if err != nil {
+					return err
+				}
+				_, suffix, _ := strings.Cut(filepath.Base(path), "_")
+				path = filepath.Join(filepath.Dir(path), fmt.Sprintf("%05d_%s", next, suffix))
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, c.content, c.mode); err != nil {
+				return err
+			}
+		case upgradeDelete:
+			if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// skeletonChanged reports whether the upgrade touches the files gof model
+// generates from.
+func skeletonChanged(changes []upgradeChange) bool {
+	for _, c := range changes {
+		if strings.Contains(c.Path, "/skeleton/") || strings.Contains(c.Path, "/skeletons/") || strings.HasSuffix(c.Path, "skeletons.test.ts") {
+			return true
+		}
+	}
+	return false
+}
@@ -15,7 +15,9 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of GoFast CLI",
 	Long:  "Print the version number of GoFast CLI",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(config.VERSION)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report.Data = map[string]string{"version": config.VERSION}
+		fmt.Fprintln(cmd.OutOrStdout(), config.VERSION)
+		return nil
 	},
 }
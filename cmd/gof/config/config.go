@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -25,18 +28,64 @@ var (
 	HelpStyle    = BlurredStyle
 )
 
+// ServerURL is the GoFast server base URL: GOF_SERVER_URL when set (tests
+// point it at a local stand-in server), otherwise SERVER_URL.
+func ServerURL() string {
+	if url := os.Getenv("GOF_SERVER_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return SERVER_URL
+}
+
+// OfflineFlag is set by the global --offline flag.
+var OfflineFlag bool
+
+// Offline reports whether gof runs without the GoFast server: credentials are
+// not checked and the template is copied from a local gofast-app checkout.
+// Enabled with --offline or GOF_OFFLINE=1.
+func Offline() bool {
+	if OfflineFlag {
+		return true
+	}
+	switch strings.ToLower(os.Getenv("GOF_OFFLINE")) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// TemplateFlag is set by the global --template flag.
+var TemplateFlag string
+
+// TemplateSource is the custom template to generate from instead of the
+// GoFast server's: --template, else GOF_TEMPLATE. It is a local directory, a
+// zip file or a git URL with an optional #ref. Empty means the server's.
+func TemplateSource() string {
+	if TemplateFlag != "" {
+		return TemplateFlag
+	}
+	return os.Getenv("GOF_TEMPLATE")
+}
+
 type Column struct {
 	Name string `json:"name"`
 	Type string `json:"type"`
 }
 
 type Model struct {
-	Name    string   `json:"name"`
+	Name string `json:"name"`
+	// Service is the Go service the model is generated into; empty is core.
+	Service string   `json:"service,omitempty"`
 	Columns []Column `json:"columns"`
 }
 
 type Config struct {
+	Schema              string    `json:"$schema,omitempty"`
+	SchemaVersion       int       `json:"schema_version"`
 	ProjectName         string    `json:"project_name"`
+	TemplateVersion     string    `json:"template_version,omitempty"`
+	Module              string    `json:"module,omitempty"`
+	CLIVersion          string    `json:"cli_version,omitempty"`
 	Services            []Service `json:"services"`
 	Models              []Model   `json:"models"`
 	Integrations        []string  `json:"integrations"`
@@ -44,42 +93,55 @@ type Config struct {
 	MonitoringPopulated bool      `json:"monitoring_populated"`
 }
 
+// DefaultModule is the Go module path of the template's code.
+const DefaultModule = "gofast"
+
+// GoModule is the project's Go module path: the one chosen with
+// 'gof init --module', else the template's.
+func (c *Config) GoModule() string {
+	if c.Module != "" {
+		return c.Module
+	}
+	return DefaultModule
+}
+
 type Service struct {
 	Name string `json:"name"`
 	Port string `json:"port"`
 }
 
-func writeConfig(cfg *Config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return err
-	}
+// errNotFound is returned when the working directory has no gofast.json.
+var errNotFound = errors.New("gofast.json config file not found. Please run 'gof init <project_name> && cd <project_name>' to create a new project")
 
-	return os.WriteFile(ConfigFileName, data, 0644)
+// ParseConfig reads gofast.json for a command that only looks at it. An
+// older schema is migrated in memory; the file itself is upgraded by the
+// next command that saves it through Open.
+func ParseConfig() (*Config, error) {
+	config, _, err := load()
+	return config, err
 }
 
-func ParseConfig() (*Config, error) {
+func load() (*Config, bool, error) {
 	data, err := os.ReadFile(ConfigFileName)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, errors.New("gofast.json config file not found. Please run 'gof init <project_name> && cd <project_name>' to create a new project")
+			return nil, false, errNotFound
 		}
-		return nil, err
+		return nil, false, err
 	}
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, err
-	}
-	return &config, nil
-}
-
-func AddModel(modelName string, columns []Column) error {
-	config, err := ParseConfig()
+	config, upgraded, err := migrate(data)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
+	if err := config.Validate(); err != nil {
+		return nil, false, err
+	}
+	return config, upgraded, nil
+}
 
-	for _, m := range config.Models {
+// AddModel records a newly generated model of service ("" for core).
+func (c *Config) AddModel(modelName, service string, columns []Column) error {
+	for _, m := range c.Models {
 		if m.Name == modelName {
 			return fmt.Errorf("model '%s' already exists in the config", modelName)
 		}
@@ -87,16 +149,22 @@ func AddModel(modelName string, columns []Column) error {
 
 	newModel := Model{
 		Name:    modelName,
+		Service: service,
 		Columns: columns,
 	}
-	config.Models = append(config.Models, newModel)
-
-	return writeConfig(config)
+	c.Models = append(c.Models, newModel)
+	return nil
 }
 
-func Initialize(projectName string) error {
-	cfg := Config{
+// New returns the configuration of a freshly initialized project: the core
+// service, the skeleton model and nothing optional.
+func New(projectName string, templateVersion string) *Config {
+	return &Config{
+		Schema:              SchemaURL,
+		SchemaVersion:       SchemaVersion,
 		ProjectName:         projectName,
+		TemplateVersion:     templateVersion,
+		CLIVersion:          VERSION,
 		InfraPopulated:      false,
 		MonitoringPopulated: false,
 		Services: []Service{
@@ -108,36 +176,28 @@ func Initialize(projectName string) error {
 				Columns: []Column{
 					{Name: "name", Type: "string"},
 					{Name: "age", Type: "number"},
-					{Name: "death", Type: "time"},
+					{Name: "death", Type: "date"},
 					{Name: "zombie", Type: "bool"},
 				},
 			},
 		},
 		Integrations: []string{},
 	}
+}
 
+// Initialize writes cfg as the config of the new project in dir.
+func Initialize(dir string, cfg *Config) error {
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(projectName+"/"+ConfigFileName, data, 0644)
+	return writeFile(filepath.Join(dir, ConfigFileName), data)
 }
 
-func IsSvelte() bool {
-	return HasService("svelte")
-}
-
-func IsTanstack() bool {
-	return HasService("tanstack")
-}
-
-func HasService(name string) bool {
-	config, err := ParseConfig()
-	if err != nil {
-		return false
-	}
-	for _, service := range config.Services {
+// HasService reports whether the project has the named service.
+func (c *Config) HasService(name string) bool {
+	for _, service := range c.Services {
 		if service.Name == name {
 			return true
 		}
@@ -145,76 +205,22 @@ func HasService(name string) bool {
 	return false
 }
 
-func AddService(name, port string) error {
-	cfg, err := ParseConfig()
-	if err != nil {
-		return err
-	}
-
-	for _, service := range cfg.Services {
-		if service.Name == name {
-			return nil
-		}
-	}
-
-	cfg.Services = append(cfg.Services, Service{Name: name, Port: port})
-	return writeConfig(cfg)
-}
-
-func MarkInfraPopulated() error {
-	cfg, err := ParseConfig()
-	if err != nil {
-		return err
-	}
-
-	if cfg.InfraPopulated {
-		return nil
-	}
-
-	cfg.InfraPopulated = true
-	return writeConfig(cfg)
-}
-
-func MarkMonitoringPopulated() error {
-	cfg, err := ParseConfig()
-	if err != nil {
-		return err
-	}
-
-	if cfg.MonitoringPopulated {
-		return nil
+// AddService records a service; one already listed is left as it is.
+func (c *Config) AddService(name, port string) {
+	if c.HasService(name) {
+		return
 	}
-
-	cfg.MonitoringPopulated = true
-	return writeConfig(cfg)
+	c.Services = append(c.Services, Service{Name: name, Port: port})
 }
 
-func HasIntegration(name string) bool {
-	cfg, err := ParseConfig()
-	if err != nil {
-		return false
-	}
-	for _, i := range cfg.Integrations {
-		if i == name {
-			return true
-		}
-	}
-	return false
+// HasIntegration reports whether the named integration was added.
+func (c *Config) HasIntegration(name string) bool {
+	return slices.Contains(c.Integrations, name)
 }
 
-func AddIntegration(name string) error {
-	cfg, err := ParseConfig()
-	if err != nil {
-		return err
+// AddIntegration records an added integration.
+func (c *Config) AddIntegration(name string) {
+	if !c.HasIntegration(name) {
+		c.Integrations = append(c.Integrations, name)
 	}
-
-	// Check if already added
-	for _, i := range cfg.Integrations {
-		if i == name {
-			return nil
-		}
-	}
-
-	cfg.Integrations = append(cfg.Integrations, name)
-	return writeConfig(cfg)
 }
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LockFileName is created next to gofast.json while a command holds it.
+const LockFileName = ConfigFileName + ".lock"
+
+// lockWait is how long Open waits for another gof command to release the
+// lock before giving up.
+const lockWait = 10 * time.Second
+
+// Project is gofast.json loaded once by a command that changes it. It holds
+// gofast.json.lock until Close, so two gof commands cannot interleave their
+// edits; changes are made on the embedded Config and written by Save.
+type Project struct {
+	*Config
+	saved []byte
+	held  bool
+}
+
+// Open locks gofast.json in the working directory and loads it. An older
+// schema is migrated in memory and written by the next Save.
+func Open() (*Project, error) {
+	if _, err := os.Stat(ConfigFileName); os.IsNotExist(err) {
+		return nil, errNotFound
+	}
+	if err := lock(); err != nil {
+		return nil, err
+	}
+	p := &Project{held: true}
+	cfg, upgraded, err := load()
+	if err != nil {
+		_ = p.Close()
+		return nil, err
+	}
+	p.Config = cfg
+	if !upgraded {
+		// Compare against the loaded config re-encoded, so a file that was
+		// merely formatted differently is not rewritten.
+		p.saved, _ = json.MarshalIndent(cfg, "", "  ")
+	}
+	return p, nil
+}
+
+// Save writes the config if it changed since it was loaded or last saved,
+// recording this gof as the last CLI version that touched the project.
+func (p *Project) Save() error {
+	data, err := json.MarshalIndent(p.Config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, p.saved) {
+		return nil
+	}
+	if p.CLIVersion != VERSION {
+		p.CLIVersion = VERSION
+		if data, err = json.MarshalIndent(p.Config, "", "  "); err != nil {
+			return err
+		}
+	}
+	if err := writeFile(ConfigFileName, data); err != nil {
+		return err
+	}
+	p.saved = data
+	return nil
+}
+
+// Close releases the lock without saving. It is safe to call more than once.
+func (p *Project) Close() error {
+	if !p.held {
+		return nil
+	}
+	p.held = false
+	return os.Remove(LockFileName)
+}
+
+// lock creates gofast.json.lock, waiting up to lockWait while another
+// command holds it. The lock records the holder's pid and start time so a
+// lock left behind by a killed gof can be recognized and removed by hand.
+func lock() error {
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(LockFileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, werr := fmt.Fprintf(f, "%d %s\n", os.Getpid(), time.Now().Format(time.RFC3339))
+			cerr := f.Close()
+			if err := errors.Join(werr, cerr); err != nil {
+				_ = os.Remove(LockFileName)
+				return fmt.Errorf("writing %s: %w", LockFileName, err)
+			}
+			return nil
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("creating %s: %w", LockFileName, err)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s is in use by another gof command%s. If no other gof is running, remove %s and try again",
+				ConfigFileName, lockHolder(), LockFileName)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// lockHolder describes the pid and start time recorded in the lock file.
+func lockHolder() string {
+	data, err := os.ReadFile(LockFileName)
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return ""
+	}
+	if _, err := strconv.Atoi(fields[0]); err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" (pid %s, since %s)", fields[0], fields[1])
+}
+
+// writeFile replaces path with data atomically: data goes to a temporary
+// file in the same directory, which is synced and renamed over path, so a
+// crash leaves either the old or the new file, never a torn one.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaVersion is the gofast.json layout this gof writes. Files with an
+// older schema_version (none at all is version 0) are upgraded by the
+// migrations below when they are read.
+const SchemaVersion = 1
+
+// SchemaURL is the JSON Schema of gofast.json, referenced from the "$schema"
+// key so editors can validate the file.
+const SchemaURL = "https://raw.githubusercontent.com/gofast-live/gofast-cli/main/cmd/gof/config/gofast.schema.json"
+
+// ColumnTypes are the model column types generation supports.
+var ColumnTypes = []string{"string", "number", "date", "bool"}
+
+// IntegrationNames are the integrations 'gof add' supports.
+var IntegrationNames = []string{"stripe", "s3", "postmark"}
+
+// migrations[i] upgrades a decoded gofast.json from schema version i to i+1.
+// A migration works on the raw JSON object, so it can rename or move keys
+// the current Config no longer has.
+var migrations = []func(raw map[string]any){
+	// 0 -> 1: the skeleton model used to be written with the "time" column
+	// type, which generation calls "date"; the S3 integration was "r2".
+	func(raw map[string]any) {
+		models, _ := raw["models"].([]any)
+		for _, m := range models {
+			model, _ := m.(map[string]any)
+			columns, _ := model["columns"].([]any)
+			for _, c := range columns {
+				if column, ok := c.(map[string]any); ok && column["type"] == "time" {
+					column["type"] = "date"
+				}
+			}
+		}
+		if integrations, ok := raw["integrations"].([]any); ok {
+			renamed := []any{}
+			for _, i := range integrations {
+				if i == "r2" {
+					i = "s3"
+				}
+				if !slices.Contains(renamed, i) {
+					renamed = append(renamed, i)
+				}
+			}
+			raw["integrations"] = renamed
+		}
+	},
+}
+
+// migrate upgrades the gofast.json in data to SchemaVersion. It reports
+// whether anything had to be upgraded.
+func migrate(data []byte) (*Config, bool, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false, fmt.Errorf("%s is not valid JSON: %w", ConfigFileName, err)
+	}
+	version := 0
+	if v, ok := raw["schema_version"]; ok {
+		f, ok := v.(float64)
+		if !ok || f != float64(int(f)) || f < 0 {
+			return nil, false, fmt.Errorf("%s: schema_version must be a whole number, got %v", ConfigFileName, v)
+		}
+		version = int(f)
+	}
+	if version > SchemaVersion {
+		return nil, false, fmt.Errorf("%s has schema_version %d, but this gof only understands up to %d. Please update gof", ConfigFileName, version, SchemaVersion)
+	}
+	for _, m := range migrations[version:] {
+		m(raw)
+	}
+	raw["schema_version"] = SchemaVersion
+	if _, ok := raw["$schema"]; !ok {
+		raw["$schema"] = SchemaURL
+	}
+
+	upgraded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(upgraded, &cfg); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", ConfigFileName, err)
+	}
+	return &cfg, version < SchemaVersion, nil
+}
+
+// Validate reports the first problem generation would trip over: a missing
+// project name, an unknown column type or integration, a duplicate model,
+// column or service, or a model of a service that is not listed.
+func (c *Config) Validate() error {
+	if c.ProjectName == "" {
+		return fmt.Errorf("%s: project_name is empty", ConfigFileName)
+	}
+	var services []string
+	for i, s := range c.Services {
+		if s.Name == "" {
+			return fmt.Errorf("%s: services[%d] has no name", ConfigFileName, i)
+		}
+		if slices.Contains(services, s.Name) {
+			return fmt.Errorf("%s: service %q is listed twice", ConfigFileName, s.Name)
+		}
+		services = append(services, s.Name)
+	}
+	var models []string
+	for i, m := range c.Models {
+		if m.Name == "" {
+			return fmt.Errorf("%s: models[%d] has no name", ConfigFileName, i)
+		}
+		if slices.Contains(models, m.Name) {
+			return fmt.Errorf("%s: model %q is listed twice", ConfigFileName, m.Name)
+		}
+		models = append(models, m.Name)
+		if m.Service != "" && !slices.Contains(services, m.Service) {
+			return fmt.Errorf("%s: model %q belongs to service %q, which is not listed in services", ConfigFileName, m.Name, m.Service)
+		}
+		var columns []string
+		for _, col := range m.Columns {
+			if !slices.Contains(ColumnTypes, col.Type) {
+				return fmt.Errorf("%s: model %q column %q has unknown type %q (valid types: %s)",
+					ConfigFileName, m.Name, col.Name, col.Type, strings.Join(ColumnTypes, ", "))
+			}
+			if slices.Contains(columns, col.Name) {
+				return fmt.Errorf("%s: model %q has column %q twice", ConfigFileName, m.Name, col.Name)
+			}
+			columns = append(columns, col.Name)
+		}
+	}
+	for _, name := range c.Integrations {
+		if !slices.Contains(IntegrationNames, name) {
+			return fmt.Errorf("%s: unknown integration %q (valid integrations: %s)",
+				ConfigFileName, name, strings.Join(IntegrationNames, ", "))
+		}
+	}
+	return nil
+}
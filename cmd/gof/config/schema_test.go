@@ -0,0 +1,124 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		columns      []Column
+		integrations []string
+		upgraded     bool
+		err          string
+	}{
+		{
+			name: "0 to 1 renames time and r2",
+			data: `{"project_name": "app", "models": [{"name": "skeleton", "columns": [{"name": "name", "type": "string"}, {"name": "due", "type": "time"}]}], "integrations": ["r2", "stripe"]}`,
+			columns: []Column{
+				{Name: "name", Type: "string"},
+				{Name: "due", Type: "date"},
+			},
+			integrations: []string{"s3", "stripe"},
+			upgraded:     true,
+		},
+		{
+			name:         "0 to 1 drops r2 when s3 is listed too",
+			data:         `{"project_name": "app", "integrations": ["s3", "r2"]}`,
+			integrations: []string{"s3"},
+			upgraded:     true,
+		},
+		{
+			name:     "0 to 1 without models or integrations",
+			data:     `{"project_name": "app"}`,
+			upgraded: true,
+		},
+		{
+			name:         "1 is left alone",
+			data:         `{"schema_version": 1, "project_name": "app", "models": [{"name": "skeleton", "columns": [{"name": "due", "type": "time"}]}], "integrations": ["r2"]}`,
+			columns:      []Column{{Name: "due", Type: "time"}},
+			integrations: []string{"r2"},
+		},
+		{
+			name: "newer than this gof",
+			data: `{"schema_version": 2, "project_name": "app"}`,
+			err:  "Please update gof",
+		},
+		{
+			name: "not a whole number",
+			data: `{"schema_version": 1.5, "project_name": "app"}`,
+			err:  "schema_version must be a whole number",
+		},
+		{
+			name: "not JSON",
+			data: `{"project_name":`,
+			err:  "is not valid JSON",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, upgraded, err := migrate([]byte(tt.data))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("migrate() error = %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if upgraded != tt.upgraded {
+				t.Errorf("migrate() upgraded = %v, want %v", upgraded, tt.upgraded)
+			}
+			if cfg.SchemaVersion != SchemaVersion || cfg.Schema != SchemaURL {
+				t.Errorf("migrate() schema = %d %q, want %d %q", cfg.SchemaVersion, cfg.Schema, SchemaVersion, SchemaURL)
+			}
+			var columns []Column
+			for _, m := range cfg.Models {
+				columns = append(columns, m.Columns...)
+			}
+			if !reflect.DeepEqual(columns, tt.columns) {
+				t.Errorf("migrate() columns = %+v, want %+v", columns, tt.columns)
+			}
+			if !reflect.DeepEqual(cfg.Integrations, tt.integrations) {
+				t.Errorf("migrate() integrations = %q, want %q", cfg.Integrations, tt.integrations)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			ProjectName:  "app",
+			Services:     []Service{{Name: "core", Port: "4000"}, {Name: "billing", Port: "4001"}},
+			Models:       []Model{{Name: "note", Columns: []Column{{Name: "title", Type: "string"}}}, {Name: "invoice", Service: "billing"}},
+			Integrations: []string{"stripe"},
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		err    string
+	}{
+		{name: "valid", change: func(c *Config) {}},
+		{name: "no project name", change: func(c *Config) { c.ProjectName = "" }, err: "project_name is empty"},
+		{name: "unknown column type", change: func(c *Config) { c.Models[0].Columns[0].Type = "time" }, err: `unknown type "time"`},
+		{name: "duplicate model", change: func(c *Config) { c.Models[1].Name = "note" }, err: `model "note" is listed twice`},
+		{name: "unknown service", change: func(c *Config) { c.Models[1].Service = "shop" }, err: `service "shop", which is not listed`},
+		{name: "unknown integration", change: func(c *Config) { c.Integrations = []string{"r2"} }, err: `unknown integration "r2"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.change(c)
+			err := c.Validate()
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Errorf("Validate() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
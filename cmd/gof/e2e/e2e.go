@@ -9,6 +9,8 @@ import (
 
 	"github.com/gertd/go-pluralize"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/naming"
 )
 
 type Column struct {
@@ -18,28 +20,14 @@ type Column struct {
 
 var pluralizeClient = pluralize.NewClient()
 
-// toPascalCase converts snake_case to PascalCase
-// e.g., "user_profile" -> "UserProfile"
-func toPascalCase(s string) string {
-	parts := strings.Split(s, "_")
-	var b strings.Builder
-	for _, p := range parts {
-		if p == "" {
-			continue
-		}
-		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
-	}
-	return b.String()
-}
-
 // generateClientE2ETest scaffolds a Playwright e2e test based on the skeleton
 // template, expanding the model configuration block with column-aware values
 // and default behaviours.
 func GenerateClientE2ETest(modelName string, columns []Column) error {
 	sourcePath := "./e2e/skeletons.test.ts"
 	pluralLower := pluralizeClient.Plural(modelName)
-	pluralCap := toPascalCase(pluralLower)
-	capitalizedModelName := toPascalCase(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
 
 	if err := os.MkdirAll("e2e", 0o755); err != nil {
 		return fmt.Errorf("creating e2e directory: %w", err)
@@ -57,17 +45,6 @@ func GenerateClientE2ETest(modelName string, columns []Column) error {
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
 	s = strings.ReplaceAll(s, "skeleton", modelName)
 
-	toTitle := func(name string) string {
-		parts := strings.Split(name, "_")
-		for i := range parts {
-			if parts[i] == "" {
-				continue
-			}
-			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
-		}
-		return strings.Join(parts, " ")
-	}
-
 	type fieldMeta struct {
 		name          string
 		label         string
@@ -83,7 +60,7 @@ func GenerateClientE2ETest(modelName string, columns []Column) error {
 	stringTimestampAssigned := false
 
 	for i, c := range columns {
-		label := toTitle(c.Name)
+		label := naming.Title(c.Name)
 		headers = append(headers, label)
 
 		meta := fieldMeta{
@@ -193,38 +170,12 @@ func GenerateClientE2ETest(modelName string, columns []Column) error {
 	fmt.Fprintf(&configB, "\t\tnewValue: %s,\n", editValueLiteral)
 	configB.WriteString("\t},\n")
 
-	replaceRegion := func(content, startMarker, endMarker, replacement string) (string, error) {
-		start := strings.Index(content, startMarker)
-		end := strings.Index(content, endMarker)
-		if start == -1 || end == -1 || end < start {
-			return content, fmt.Errorf("markers %q .. %q not found", startMarker, endMarker)
-		}
-		start += len(startMarker)
-		return content[:start] + "\n" + replacement + content[end:], nil
-	}
-
 	var rErr error
-	s, rErr = replaceRegion(s, "// GF_MODEL_CONFIG_START", "// GF_MODEL_CONFIG_END", configB.String())
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "MODEL_CONFIG", configB.String())
 	if rErr != nil {
 		return fmt.Errorf("replacing model config: %w", rErr)
 	}
 
-	markers := []string{"// GF_MODEL_CONFIG_START", "// GF_MODEL_CONFIG_END"}
-	var outLines []string
-	for line := range strings.SplitSeq(s, "\n") {
-		skip := false
-		for _, marker := range markers {
-			if strings.Contains(line, marker) {
-				skip = true
-				break
-			}
-		}
-		if !skip {
-			outLines = append(outLines, line)
-		}
-	}
-	s = strings.Join(outLines, "\n")
-
 	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
 		return fmt.Errorf("writing e2e test %s: %w", destPath, err)
 	}
@@ -261,13 +212,8 @@ func ComputeUserAccess(numModels int) int64 {
 }
 
 // UpdateSeedDevUser updates the DEV_USER_ACCESS value in scripts/seed_dev_user.sh
-// based on the number of models in the project.
-func UpdateSeedDevUser() error {
-	cfg, err := config.ParseConfig()
-	if err != nil {
-		return fmt.Errorf("parsing config: %w", err)
-	}
-
+// based on the number of models in cfg.
+func UpdateSeedDevUser(cfg *config.Config) error {
 	path := "scripts/seed_dev_user.sh"
 	content, err := os.ReadFile(path)
 	if err != nil {
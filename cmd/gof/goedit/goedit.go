@@ -0,0 +1,488 @@
+package goedit
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is a parsed Go source file that collects text insertions and removals
+// located on its syntax tree. They are applied against the original source, so
+// comments and formatting outside the edited lines are preserved byte for byte.
+type File struct {
+	name    string
+	src     []byte
+	fset    *token.FileSet
+	file    *ast.File
+	inserts []insertion
+}
+
+// insertion replaces src[off:end] with text; end == off for a pure insertion.
+type insertion struct {
+	off  int
+	end  int
+	seq  int
+	text string
+}
+
+// MergeError reports a merge point that could not be located, with the exact
+// position in the file that was searched.
+type MergeError struct {
+	Pos token.Position
+	Msg string
+}
+
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Scope is the region of the file an Anchor is resolved in: the parentheses of
+// an import declaration, the braces of a struct type or a function body.
+type Scope struct {
+	Open  token.Pos
+	Close token.Pos
+	Nodes []ast.Node
+}
+
+// Anchor resolves an insertion offset inside a scope. It returns false when the
+// anchor does not apply, so callers can chain fallbacks.
+type Anchor func(f *File, s Scope) (int, bool)
+
+// ParseFile reads and parses the Go file at path.
+func ParseFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, src)
+}
+
+// Parse parses src as a Go file named name.
+func Parse(name string, src []byte) (*File, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return &File{name: name, src: src, fset: fset, file: file}, nil
+}
+
+// Position converts a token position into a file:line:column position.
+func (f *File) Position(p token.Pos) token.Position {
+	return f.fset.Position(p)
+}
+
+// Offset converts a token position into a byte offset in the source.
+func (f *File) Offset(p token.Pos) int {
+	return f.fset.Position(p).Offset
+}
+
+// PositionAt converts a byte offset into a file:line:column position.
+func (f *File) PositionAt(off int) token.Position {
+	tf := f.fset.File(f.file.Pos())
+	return tf.Position(tf.Pos(off))
+}
+
+// Text returns the source text of a node.
+func (f *File) Text(n ast.Node) string {
+	return string(f.src[f.Offset(n.Pos()):f.Offset(n.End())])
+}
+
+// Syntax returns the parsed file. It must not be modified; edits go through
+// Insert and Remove.
+func (f *File) Syntax() *ast.File {
+	return f.file
+}
+
+// Source returns the original source the file was parsed from.
+func (f *File) Source() []byte {
+	return f.src
+}
+
+// HasImport reports whether path is imported, regardless of alias.
+func (f *File) HasImport(path string) bool {
+	for _, imp := range f.file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportScope returns the first parenthesized import declaration.
+func (f *File) ImportScope() (Scope, error) {
+	for _, decl := range f.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		nodes := make([]ast.Node, len(gen.Specs))
+		for i, spec := range gen.Specs {
+			nodes[i] = spec
+		}
+		return Scope{Open: gen.Lparen, Close: gen.Rparen, Nodes: nodes}, nil
+	}
+	return Scope{}, &MergeError{Pos: f.Position(f.file.Name.End()), Msg: "no parenthesized import declaration found"}
+}
+
+// FuncScope returns the body of the top-level function name.
+func (f *File) FuncScope(name string) (Scope, error) {
+	for _, decl := range f.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != name || fn.Body == nil {
+			continue
+		}
+		nodes := make([]ast.Node, len(fn.Body.List))
+		for i, stmt := range fn.Body.List {
+			nodes[i] = stmt
+		}
+		return Scope{Open: fn.Body.Lbrace, Close: fn.Body.Rbrace, Nodes: nodes}, nil
+	}
+	return Scope{}, &MergeError{Pos: f.Position(f.file.Package), Msg: fmt.Sprintf("function %s not found", name)}
+}
+
+// StructScope returns the field list of the struct type name.
+func (f *File) StructScope(name string) (Scope, error) {
+	var scope Scope
+	found := false
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != name {
+			return true
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok {
+			return true
+		}
+		nodes := make([]ast.Node, len(st.Fields.List))
+		for i, field := range st.Fields.List {
+			nodes[i] = field
+		}
+		scope = Scope{Open: st.Fields.Opening, Close: st.Fields.Closing, Nodes: nodes}
+		found = true
+		return false
+	})
+	if !found {
+		return Scope{}, &MergeError{Pos: f.Position(f.file.Package), Msg: fmt.Sprintf("struct type %s not found", name)}
+	}
+	return scope, nil
+}
+
+// Enclosing describes where an offset sits in the syntax tree.
+type Enclosing struct {
+	// Kind is "import", "struct", "func" or "" when the offset is at top level.
+	Kind string
+	// Name is the struct type or function name.
+	Name string
+	// Next is the first node of the scope that starts after the offset, if any.
+	Next ast.Node
+}
+
+// EnclosingAt reports which import declaration, struct type or function body
+// contains the byte offset off.
+func (f *File) EnclosingAt(off int) Enclosing {
+	pos := f.fset.File(f.file.Pos()).Pos(off)
+	for _, decl := range f.file.Decls {
+		if pos < decl.Pos() || pos > decl.End() {
+			continue
+		}
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				return Enclosing{Kind: "import", Next: nextSpec(d.Specs, pos)}
+			}
+			for _, spec := range d.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || pos < st.Fields.Opening || pos > st.Fields.Closing {
+					continue
+				}
+				var next ast.Node
+				for _, field := range st.Fields.List {
+					if field.Pos() > pos {
+						next = field
+						break
+					}
+				}
+				return Enclosing{Kind: "struct", Name: ts.Name.Name, Next: next}
+			}
+		case *ast.FuncDecl:
+			if d.Body == nil || d.Recv != nil || pos < d.Body.Lbrace || pos > d.Body.Rbrace {
+				continue
+			}
+			var next ast.Node
+			for _, stmt := range d.Body.List {
+				if stmt.Pos() > pos {
+					next = stmt
+					break
+				}
+			}
+			return Enclosing{Kind: "func", Name: d.Name.Name, Next: next}
+		}
+	}
+	return Enclosing{}
+}
+
+func nextSpec(specs []ast.Spec, pos token.Pos) ast.Node {
+	for _, spec := range specs {
+		if spec.Pos() > pos {
+			return spec
+		}
+	}
+	return nil
+}
+
+// Insert adds code at the first anchor that resolves inside scope. The code is
+// inserted as whole lines; indentation is normalised when the file is formatted.
+func (f *File) Insert(scope Scope, code string, what string, anchors ...Anchor) error {
+	for _, anchor := range anchors {
+		off, ok := anchor(f, scope)
+		if !ok {
+			continue
+		}
+		if !strings.HasSuffix(code, "\n") {
+			code += "\n"
+		}
+		f.inserts = append(f.inserts, insertion{off: off, end: off, seq: len(f.inserts), text: code})
+		return nil
+	}
+	return &MergeError{Pos: f.Position(scope.Open), Msg: "cannot find merge point for " + what}
+}
+
+// Remove deletes the lines of node n, together with the comment lines right
+// above it up to a GF_ marker. n must start and end its lines,
+// as statements, declarations and import specs of gofmt'd code do.
+func (f *File) Remove(n ast.Node) {
+	start := f.lineStart(f.Offset(n.Pos()))
+	line := f.Position(n.Pos()).Line
+	for _, group := range f.file.Comments {
+		if f.Position(group.End()).Line != line-1 {
+			continue
+		}
+		// Only the comment lines directly above n, up to a marker.
+		for i := len(group.List) - 1; i >= 0; i-- {
+			c := group.List[i]
+			cStart := f.lineStart(f.Offset(c.Pos()))
+			if f.Position(c.End()).Line != line-1 || strings.Contains(c.Text, "GF_") ||
+				strings.TrimSpace(string(f.src[cStart:f.Offset(c.Pos())])) != "" {
+				break
+			}
+			start, line = cStart, f.Position(c.Pos()).Line
+		}
+		break
+	}
+	f.inserts = append(f.inserts, insertion{off: start, end: f.lineEnd(f.Offset(n.End())), seq: len(f.inserts)})
+}
+
+// ContainsNode reports whether scope already has a node whose source matches
+// text, ignoring whitespace differences.
+func (f *File) ContainsNode(scope Scope, text string) bool {
+	want := normalize(text)
+	for _, n := range scope.Nodes {
+		if normalize(f.Text(n)) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Bytes applies all insertions and removals and returns the gofmt-formatted
+// result.
+func (f *File) Bytes() ([]byte, error) {
+	inserts := append([]insertion(nil), f.inserts...)
+	sort.SliceStable(inserts, func(i, j int) bool {
+		if inserts[i].off != inserts[j].off {
+			return inserts[i].off < inserts[j].off
+		}
+		return inserts[i].seq < inserts[j].seq
+	})
+	var b bytes.Buffer
+	last := 0
+	for _, ins := range inserts {
+		// Overlapping removals only remove their lines once.
+		if ins.off > last {
+			b.Write(f.src[last:ins.off])
+		}
+		b.WriteString(ins.text)
+		last = max(last, ins.end)
+	}
+	b.Write(f.src[last:])
+	out, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting merged %s: %w", f.name, err)
+	}
+	return out, nil
+}
+
+// WriteFile applies all insertions and removals and writes the result to path.
+func (f *File) WriteFile(path string) error {
+	out, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o644)
+}
+
+// lineStart returns the offset of the first byte of the line containing off.
+func (f *File) lineStart(off int) int {
+	return bytes.LastIndexByte(f.src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line containing off.
+func (f *File) lineEnd(off int) int {
+	i := bytes.IndexByte(f.src[off:], '\n')
+	if i == -1 {
+		return len(f.src)
+	}
+	return off + i + 1
+}
+
+func (f *File) commentIn(s Scope, substr string) *ast.Comment {
+	for _, group := range f.file.Comments {
+		for _, c := range group.List {
+			if c.Pos() > s.Open && c.End() < s.Close && strings.Contains(c.Text, substr) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// BeforeComment anchors at the line of the first comment in scope containing substr.
+func BeforeComment(substr string) Anchor {
+	return func(f *File, s Scope) (int, bool) {
+		c := f.commentIn(s, substr)
+		if c == nil {
+			return 0, false
+		}
+		return f.lineStart(f.Offset(c.Pos())), true
+	}
+}
+
+// AfterComment anchors on the line following the first comment in scope containing substr.
+func AfterComment(substr string) Anchor {
+	return func(f *File, s Scope) (int, bool) {
+		c := f.commentIn(s, substr)
+		if c == nil {
+			return 0, false
+		}
+		return f.lineEnd(f.Offset(c.End())), true
+	}
+}
+
+// BeforeNode anchors at the line of the first node in scope matching match.
+func BeforeNode(match func(f *File, n ast.Node) bool) Anchor {
+	return func(f *File, s Scope) (int, bool) {
+		for _, n := range s.Nodes {
+			if match(f, n) {
+				return f.lineStart(f.Offset(n.Pos())), true
+			}
+		}
+		return 0, false
+	}
+}
+
+// AfterLastNode anchors on the line following the last node in scope matching match.
+func AfterLastNode(match func(f *File, n ast.Node) bool) Anchor {
+	return func(f *File, s Scope) (int, bool) {
+		for i := len(s.Nodes) - 1; i >= 0; i-- {
+			if match(f, s.Nodes[i]) {
+				return f.lineEnd(f.Offset(s.Nodes[i].End())), true
+			}
+		}
+		return 0, false
+	}
+}
+
+// AtEnd anchors just before the closing token of the scope.
+func AtEnd() Anchor {
+	return func(f *File, s Scope) (int, bool) {
+		off := f.Offset(s.Close)
+		start := f.lineStart(off)
+		if strings.TrimSpace(string(f.src[start:off])) != "" {
+			// Closing token shares a line with other code; break the line.
+			return off, true
+		}
+		return start, true
+	}
+}
+
+// AfterNode anchors on the line after node n.
+func AfterNode(n ast.Node) Anchor {
+	return func(f *File, _ Scope) (int, bool) {
+		return f.lineEnd(f.Offset(n.End())), true
+	}
+}
+
+// SameText matches nodes whose source equals text, ignoring whitespace.
+func SameText(text string) func(f *File, n ast.Node) bool {
+	want := normalize(text)
+	return func(f *File, n ast.Node) bool {
+		return normalize(f.Text(n)) == want
+	}
+}
+
+// IsReturn matches return statements.
+func IsReturn(_ *File, n ast.Node) bool {
+	_, ok := n.(*ast.ReturnStmt)
+	return ok
+}
+
+// CallsSelector matches expression statements calling recv.name(...).
+func CallsSelector(recv, name string) func(f *File, n ast.Node) bool {
+	return func(_ *File, n ast.Node) bool {
+		es, ok := n.(*ast.ExprStmt)
+		if !ok {
+			return false
+		}
+		call, ok := es.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != name {
+			return false
+		}
+		id, ok := sel.X.(*ast.Ident)
+		return ok && id.Name == recv
+	}
+}
+
+// AssignsComposite matches `x := pkg.Type{...}` statements whose composite
+// literal type is named typeName.
+func AssignsComposite(typeName string) func(f *File, n ast.Node) bool {
+	return func(_ *File, n ast.Node) bool {
+		as, ok := n.(*ast.AssignStmt)
+		if !ok || len(as.Rhs) != 1 {
+			return false
+		}
+		lit, ok := as.Rhs[0].(*ast.CompositeLit)
+		if !ok {
+			return false
+		}
+		switch t := lit.Type.(type) {
+		case *ast.SelectorExpr:
+			return t.Sel.Name == typeName
+		case *ast.Ident:
+			return t.Name == typeName
+		}
+		return false
+	}
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
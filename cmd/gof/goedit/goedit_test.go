@@ -0,0 +1,220 @@
+package goedit
+
+import (
+	"errors"
+	"go/ast"
+	"strings"
+	"testing"
+)
+
+const mainSrc = `package main
+
+import (
+	"log"
+
+	// GF_MAIN_IMPORT_SERVICES_START
+	noteSvc "app/domain/note"
+	// GF_MAIN_IMPORT_SERVICES_END
+)
+
+func main() {
+	store := newStore()
+	noteDeps := noteSvc.Deps{Store: store}
+
+	// GF_MAIN_MOUNT_ROUTES_START
+	// Notes
+	server.Mount(note(noteDeps))
+	// GF_MAIN_MOUNT_ROUTES_END
+
+	log.Fatal(server.Start())
+}
+`
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   func(f *File) (Scope, error)
+		code    string
+		anchors []Anchor
+		want    string // the line the code must follow
+	}{
+		{
+			name:    "before marker",
+			scope:   (*File).ImportScope,
+			code:    `userSvc "app/domain/user"`,
+			anchors: []Anchor{BeforeComment("GF_MAIN_IMPORT_SERVICES_END"), AtEnd()},
+			want:    `	noteSvc "app/domain/note"`,
+		},
+		{
+			name:    "after marker",
+			scope:   func(f *File) (Scope, error) { return f.FuncScope("main") },
+			code:    "server.Mount(user(userDeps))",
+			anchors: []Anchor{AfterComment("GF_MAIN_MOUNT_ROUTES_START")},
+			want:    "	// GF_MAIN_MOUNT_ROUTES_START",
+		},
+		{
+			name:    "fallback after last matching node",
+			scope:   func(f *File) (Scope, error) { return f.FuncScope("main") },
+			code:    "userDeps := userSvc.Deps{Store: store}",
+			anchors: []Anchor{BeforeComment("GF_MAIN_INIT_SERVICES_END"), AfterLastNode(AssignsComposite("Deps"))},
+			want:    "	noteDeps := noteSvc.Deps{Store: store}",
+		},
+		{
+			name:    "after last call",
+			scope:   func(f *File) (Scope, error) { return f.FuncScope("main") },
+			code:    "server.Mount(user(userDeps))",
+			anchors: []Anchor{AfterLastNode(CallsSelector("server", "Mount"))},
+			want:    "	server.Mount(note(noteDeps))",
+		},
+		{
+			name:    "at end",
+			scope:   func(f *File) (Scope, error) { return f.FuncScope("main") },
+			code:    "_ = store",
+			anchors: []Anchor{AtEnd()},
+			want:    "	log.Fatal(server.Start())",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse("main.go", []byte(mainSrc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			scope, err := tt.scope(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Insert(scope, tt.code, tt.name, tt.anchors...); err != nil {
+				t.Fatal(err)
+			}
+			out, err := f.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(out), tt.want+"\n\t"+tt.code+"\n") {
+				t.Errorf("Insert() did not add %q after %q:\n%s", tt.code, tt.want, out)
+			}
+			// Everything else is kept as it was.
+			if got := strings.Replace(string(out), "\t"+tt.code+"\n", "", 1); got != mainSrc {
+				t.Errorf("Insert() changed more than the inserted line:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestInsertNoAnchor(t *testing.T) {
+	f, err := Parse("main.go", []byte(mainSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := f.FuncScope("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Insert(body, "x := 1", "x", BeforeComment("GF_MISSING"), AfterLastNode(CallsSelector("router", "Handle")))
+	var merr *MergeError
+	if !errors.As(err, &merr) || merr.Pos.Line != 11 {
+		t.Errorf("Insert() error = %v, want a MergeError at main's body on line 11", err)
+	}
+	if _, err := f.FuncScope("start"); !errors.As(err, &merr) {
+		t.Errorf("FuncScope() error = %v, want a MergeError", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name   string
+		remove func(f *File, body Scope) []ast.Node
+		want   string
+	}{
+		{
+			name: "statement with its comment, not the marker",
+			remove: func(f *File, body Scope) []ast.Node {
+				return []ast.Node{body.Nodes[2]}
+			},
+			want: strings.Replace(mainSrc, "\t// Notes\n\tserver.Mount(note(noteDeps))\n", "", 1),
+		},
+		{
+			name: "import below a marker",
+			remove: func(f *File, body Scope) []ast.Node {
+				return []ast.Node{f.Syntax().Imports[1]}
+			},
+			// gofmt drops the blank line between the last spec, "log", and the
+			// closing parenthesis.
+			want: strings.Replace(mainSrc, "\"log\"\n\n\t// GF_MAIN_IMPORT_SERVICES_START\n\tnoteSvc \"app/domain/note\"\n",
+				"\"log\"\n\t// GF_MAIN_IMPORT_SERVICES_START\n", 1),
+		},
+		{
+			name: "same statement twice",
+			remove: func(f *File, body Scope) []ast.Node {
+				return []ast.Node{body.Nodes[1], body.Nodes[1]}
+			},
+			want: strings.Replace(mainSrc, "\tnoteDeps := noteSvc.Deps{Store: store}\n", "", 1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse("main.go", []byte(mainSrc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := f.FuncScope("main")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, n := range tt.remove(f, body) {
+				f.Remove(n)
+			}
+			out, err := f.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("Remove() =\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveAndInsert(t *testing.T) {
+	f, err := Parse("main.go", []byte(mainSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := f.FuncScope("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Remove(body.Nodes[1])
+	if err := f.Insert(body, "_ = store", "use of store", AfterNode(body.Nodes[0])); err != nil {
+		t.Fatal(err)
+	}
+	out, err := f.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(mainSrc, "\tnoteDeps := noteSvc.Deps{Store: store}\n", "\t_ = store\n", 1)
+	if string(out) != want {
+		t.Errorf("Bytes() =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestContainsNode(t *testing.T) {
+	f, err := Parse("main.go", []byte(mainSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := f.FuncScope("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.ContainsNode(body, "noteDeps   :=\n\tnoteSvc.Deps{Store:  store}") {
+		t.Error("ContainsNode() did not find the deps line written with other spacing")
+	}
+	if f.ContainsNode(body, "userDeps := userSvc.Deps{Store: store}") {
+		t.Error("ContainsNode() found a line that is not there")
+	}
+	if !f.HasImport("app/domain/note") || f.HasImport("app/domain/user") {
+		t.Error("HasImport() is wrong")
+	}
+}
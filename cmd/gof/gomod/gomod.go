@@ -0,0 +1,158 @@
+// Package gomod changes the Go module path of a project generated from the
+// template, whose Go code lives in the "gofast" module rooted at app/.
+package gomod
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+)
+
+// Check reports whether path can be used as a module path: slash-separated
+// elements of letters, digits and ".-_~", none empty or starting with a dot.
+func Check(path string) error {
+	if path == "" {
+		return fmt.Errorf("module path is empty")
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" {
+			return fmt.Errorf("module path %q has an empty element", path)
+		}
+		if elem[0] == '.' || elem[0] == '-' {
+			return fmt.Errorf("module path %q has an element starting with %q", path, elem[0])
+		}
+		for _, r := range elem {
+			if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune(".-_~", r)) {
+				return fmt.Errorf("module path %q contains invalid character %q", path, r)
+			}
+		}
+	}
+	return nil
+}
+
+// goPackage matches the go_package option of a proto file.
+var goPackage = regexp.MustCompile(`(option\s+go_package\s*=\s*")([^"]*)(")`)
+
+// Rewrite moves the Go code below root from module from to module to: the
+// module directive of go.mod files, import paths in .go files and the
+// go_package option of .proto files. Paths outside from are left alone.
+func Rewrite(root, from, to string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if markers.SkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		var rewrite func([]byte) ([]byte, error)
+		switch {
+		case d.Name() == "go.mod":
+			rewrite = func(src []byte) ([]byte, error) { return rewriteGoMod(src, from, to), nil }
+		case filepath.Ext(path) == ".go":
+			rewrite = func(src []byte) ([]byte, error) { return rewriteImports(path, src, from, to) }
+		case filepath.Ext(path) == ".proto":
+			rewrite = func(src []byte) ([]byte, error) { return rewriteGoPackage(src, from, to), nil }
+		default:
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := rewrite(src)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(out, src) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, out, info.Mode().Perm())
+	})
+}
+
+// movePath returns path moved from module from to module to, and whether it
+// was inside from.
+func movePath(path, from, to string) (string, bool) {
+	if path == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(path, from+"/"); ok {
+		return to + "/" + rest, true
+	}
+	return path, false
+}
+
+func rewriteGoMod(src []byte, from, to string) []byte {
+	lines := strings.SplitAfter(string(src), "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if moved, ok := movePath(strings.Trim(fields[1], `"`), from, to); ok {
+				lines[i] = strings.Replace(line, fields[1], moved, 1)
+			}
+			break
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// rewriteImports replaces the import path literals in place, so the rest of
+// the file is kept byte for byte.
+func rewriteImports(name string, src []byte, from, to string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	var b bytes.Buffer
+	last := 0
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		moved, ok := movePath(path, from, to)
+		if !ok {
+			continue
+		}
+		start := fset.Position(imp.Path.Pos()).Offset
+		end := fset.Position(imp.Path.End()).Offset
+		b.Write(src[last:start])
+		b.WriteString(strconv.Quote(moved))
+		last = end
+	}
+	b.Write(src[last:])
+	return b.Bytes(), nil
+}
+
+func rewriteGoPackage(src []byte, from, to string) []byte {
+	return goPackage.ReplaceAllFunc(src, func(m []byte) []byte {
+		parts := goPackage.FindSubmatch(m)
+		// go_package may name the Go package after a ';'.
+		path, pkg, _ := strings.Cut(string(parts[2]), ";")
+		moved, ok := movePath(path, from, to)
+		if !ok {
+			return m
+		}
+		if pkg != "" {
+			moved += ";" + pkg
+		}
+		return []byte(string(parts[1]) + moved + string(parts[3]))
+	})
+}
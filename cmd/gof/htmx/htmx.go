@@ -0,0 +1,288 @@
+// Package htmx generates the pages of the htmx client for a model: a Go
+// handler that calls service-core over ConnectRPC and html/template pages
+// enhanced with htmx, from the skeleton in app/service-htmx. The client is
+// plain Go in the app module, so nothing here needs Node.
+package htmx
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gertd/go-pluralize"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/goedit"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/naming"
+)
+
+type Column struct {
+	Name string
+	Type string
+}
+
+var pluralizeClient = pluralize.NewClient()
+
+const clientDir = "app/service-htmx"
+
+// GetModelPath returns the client-side path for a model (e.g., "/models/notes" for "note")
+func GetModelPath(modelName string) string {
+	return "/models/" + pluralizeClient.Plural(modelName)
+}
+
+// FormatProject gofmts the client's Go files. root is the project
+// directory. Unlike the JavaScript clients there is nothing to install.
+func FormatProject(root string) error {
+	return filepath.WalkDir(filepath.Join(root, clientDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".go" {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		formatted, err := format.Source(src)
+		if err != nil {
+			return fmt.Errorf("formatting %s: %w", path, err)
+		}
+		if bytes.Equal(formatted, src) {
+			return nil
+		}
+		return os.WriteFile(path, formatted, 0o644)
+	})
+}
+
+func GenerateHtmxScaffolding(modelName string, columns []Column) error {
+	if err := generateHandler(modelName, columns); err != nil {
+		return fmt.Errorf("generating client handler: %w", err)
+	}
+	if err := generateListPage(modelName, columns); err != nil {
+		return fmt.Errorf("generating client list page: %w", err)
+	}
+	if err := generateDetailPage(modelName, columns); err != nil {
+		return fmt.Errorf("generating client detail page: %w", err)
+	}
+	if err := wireMain(modelName); err != nil {
+		return fmt.Errorf("wiring client main.go: %w", err)
+	}
+	return nil
+}
+
+// renameSkeleton replaces the skeleton tokens of a handler or template.
+// URL paths, template paths and the route parameter keep the snake_case
+// model name, so they match the other clients and the e2e tests; Go and
+// template identifiers get PascalCase or camelCase.
+func renameSkeleton(s, modelName string) string {
+	pluralLower := pluralizeClient.Plural(modelName)
+	s = strings.ReplaceAll(s, "skeleton_id", modelName+"_id")
+	s = strings.ReplaceAll(s, "models/skeletons", "models/"+pluralLower)
+	s = strings.ReplaceAll(s, "Skeletons", naming.Pascal(pluralLower))
+	s = strings.ReplaceAll(s, "Skeleton", naming.Pascal(modelName))
+	s = strings.ReplaceAll(s, "skeletons", naming.Camel(pluralLower))
+	s = strings.ReplaceAll(s, "skeleton", naming.Camel(modelName))
+	return s
+}
+
+// generateHandler copies the skeleton handler, which serves the list and
+// detail pages and forwards form posts to core, and fills in how a
+// submitted form becomes the proto message.
+func generateHandler(modelName string, columns []Column) error {
+	sourcePath := clientDir + "/handlers/skeleton.go"
+	destPath := filepath.Join(clientDir, "handlers", modelName+".go")
+
+	contentBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading template file %s: %w", sourcePath, err)
+	}
+	s := renameSkeleton(string(contentBytes), modelName)
+
+	// Form field names stay snake_case; proto fields are PascalCase. Numbers
+	// and dates are strings in proto, validated by core.
+	var b strings.Builder
+	for _, c := range columns {
+		field := naming.Pascal(c.Name)
+		if c.Type == "bool" {
+			b.WriteString("\t\t" + field + ": r.FormValue(\"" + c.Name + "\") == \"on\",\n")
+			continue
+		}
+		b.WriteString("\t\t" + field + ": r.FormValue(\"" + c.Name + "\"),\n")
+	}
+
+	s, err = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FORMDATA", strings.TrimRight(b.String(), "\n"))
+	if err != nil {
+		return fmt.Errorf("replacing form data: %w", err)
+	}
+	formatted, err := format.Source([]byte(s))
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", destPath, err)
+	}
+	if err := os.WriteFile(destPath, formatted, 0o644); err != nil {
+		return fmt.Errorf("writing client handler %s: %w", destPath, err)
+	}
+	return nil
+}
+
+func generateListPage(modelName string, columns []Column) error {
+	sourcePath := clientDir + "/templates/models/skeletons/list.html"
+	pluralLower := pluralizeClient.Plural(modelName)
+
+	destDir := filepath.Join(clientDir, "templates/models", pluralLower)
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return fmt.Errorf("creating destination directory %s: %w", destDir, err)
+	}
+	destPath := filepath.Join(destDir, "list.html")
+
+	contentBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading template file %s: %w", sourcePath, err)
+	}
+	s := renameSkeleton(string(contentBytes), modelName)
+
+	var h strings.Builder
+	for _, c := range columns {
+		h.WriteString("                <th role=\"columnheader\">" + naming.Title(c.Name) + "</th>\n")
+	}
+	h.WriteString("                <th role=\"columnheader\">Created</th>\n")
+	h.WriteString("                <th role=\"columnheader\">Updated</th>\n")
+
+	// Cells render inside {{ range }} over the proto messages; the
+	// template's "date" func formats RFC3339 timestamps.
+	var b strings.Builder
+	for _, c := range columns {
+		field := naming.Pascal(c.Name)
+		switch c.Type {
+		case "date":
+			b.WriteString("                    <td>{{ date ." + field + " }}</td>\n")
+		case "bool":
+			b.WriteString("                    <td>{{ if ." + field + " }}Yes{{ else }}No{{ end }}</td>\n")
+		default:
+			b.WriteString("                    <td>{{ ." + field + " }}</td>\n")
+		}
+	}
+	b.WriteString("                    <td>{{ date .Created }}</td>\n")
+	b.WriteString("                    <td>{{ date .Updated }}</td>\n")
+
+	s, err = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_HEADERS", h.String())
+	if err != nil {
+		return fmt.Errorf("replacing headers: %w", err)
+	}
+	s, err = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_CELLS", b.String())
+	if err != nil {
+		return fmt.Errorf("replacing cells: %w", err)
+	}
+
+	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
+		return fmt.Errorf("writing client list page %s: %w", destPath, err)
+	}
+	return nil
+}
+
+func generateDetailPage(modelName string, columns []Column) error {
+	sourcePath := clientDir + "/templates/models/skeletons/detail.html"
+	pluralLower := pluralizeClient.Plural(modelName)
+	capitalizedModelName := naming.Pascal(modelName)
+
+	destDir := filepath.Join(clientDir, "templates/models", pluralLower)
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return fmt.Errorf("creating destination directory %s: %w", destDir, err)
+	}
+	destPath := filepath.Join(destDir, "detail.html")
+
+	contentBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading template file %s: %w", sourcePath, err)
+	}
+	s := renameSkeleton(string(contentBytes), modelName)
+
+	// The page gets the message as .<Model>; it is empty on the create page.
+	// The template's "inputDate" func turns RFC3339 into YYYY-MM-DD.
+	var uiB strings.Builder
+	for _, c := range columns {
+		label := naming.Title(c.Name)
+		value := "." + capitalizedModelName + "." + naming.Pascal(c.Name)
+		switch c.Type {
+		case "string", "number", "date":
+			inputType, hint, attr := "text", "Enter at least 3 characters", "{{ "+value+" }}"
+			switch c.Type {
+			case "number":
+				inputType, hint = "number", "Enter a positive number"
+			case "date":
+				inputType, hint, attr = "date", "Select a valid date", "{{ inputDate "+value+" }}"
+			}
+			uiB.WriteString("        <label class=\"label\" for=\"" + c.Name + "\">" + label + "</label>\n")
+			uiB.WriteString("        <div>\n")
+			uiB.WriteString("            <input\n")
+			uiB.WriteString("                type=\"" + inputType + "\"\n")
+			uiB.WriteString("                id=\"" + c.Name + "\"\n")
+			uiB.WriteString("                name=\"" + c.Name + "\"\n")
+			uiB.WriteString("                required\n")
+			uiB.WriteString("                class=\"input input-bordered validator w-full\"\n")
+			uiB.WriteString("                value=\"" + attr + "\"\n")
+			uiB.WriteString("            />\n")
+			uiB.WriteString("            <div class=\"validator-hint\">" + hint + "</div>\n")
+			uiB.WriteString("        </div>\n\n")
+		case "bool":
+			uiB.WriteString("        <label class=\"label cursor-pointer my-2\" for=\"" + c.Name + "\">\n")
+			uiB.WriteString("            <span class=\"label-text\">" + label + "</span>\n")
+			uiB.WriteString("            <input\n")
+			uiB.WriteString("                id=\"" + c.Name + "\"\n")
+			uiB.WriteString("                name=\"" + c.Name + "\"\n")
+			uiB.WriteString("                type=\"checkbox\"\n")
+			uiB.WriteString("                class=\"toggle\"\n")
+			uiB.WriteString("                {{ if " + value + " }}checked{{ end }}\n")
+			uiB.WriteString("            />\n")
+			uiB.WriteString("        </label>\n\n")
+		}
+	}
+
+	s, err = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FIELDS", strings.TrimRight(uiB.String(), "\n"))
+	if err != nil {
+		return fmt.Errorf("replacing UI fields: %w", err)
+	}
+
+	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
+		return fmt.Errorf("writing client detail page %s: %w", destPath, err)
+	}
+	return nil
+}
+
+// wireMain registers the model's handler in the client's main.go: a copy of
+// the skeleton's handlers.RegisterSkeletons(...) call, placed before the
+// GF_MAIN_MOUNT_ROUTES_END marker or after the last Register call.
+func wireMain(modelName string) error {
+	path := clientDir + "/main.go"
+	f, err := goedit.ParseFile(path)
+	if err != nil {
+		return err
+	}
+	body, err := f.FuncScope("main")
+	if err != nil {
+		return err
+	}
+	isSkeleton := goedit.CallsSelector("handlers", "RegisterSkeletons")
+	var skeletonCall string
+	for _, n := range body.Nodes {
+		if isSkeleton(f, n) {
+			skeletonCall = f.Text(n)
+			break
+		}
+	}
+	if skeletonCall == "" {
+		return fmt.Errorf("no handlers.RegisterSkeletons call in %s", path)
+	}
+	register := naming.Pascal(pluralizeClient.Plural(modelName))
+	call := strings.Replace(skeletonCall, "RegisterSkeletons", "Register"+register, 1)
+	if f.ContainsNode(body, call) {
+		return nil
+	}
+	if err := f.Insert(body, call, "handler registration",
+		goedit.BeforeComment("GF_MAIN_MOUNT_ROUTES_END"),
+		goedit.AfterLastNode(isSkeleton),
+	); err != nil {
+		return err
+	}
+	return f.WriteFile(path)
+}
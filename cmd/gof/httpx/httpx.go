@@ -0,0 +1,77 @@
+// Package httpx is the HTTP client every call to the GoFast server goes
+// through: bounded connect/header timeouts, proxy support from the
+// environment and retries with backoff for transient failures.
+package httpx
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Attempts is how often a request is tried before giving up.
+const Attempts = 3
+
+var client = &http.Client{
+	Transport: &http.Transport{
+		// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+	},
+	// No overall timeout: template archives are streamed and may be large.
+	// Stalled connections are caught by the dial, TLS and header timeouts.
+}
+
+// backoff is the wait before the given retry (1-based): 500ms, 1s, 2s, ...
+func backoff(retry int) time.Duration {
+	return time.Duration(500<<(retry-1)) * time.Millisecond
+}
+
+// Get performs a GET request with the given headers, retrying on network
+// errors, 429 and 5xx responses. The caller closes the body of the returned
+// response, whatever its status.
+func Get(url string, header http.Header) (*http.Response, error) {
+	var lastErr error
+	for attempt := 1; attempt <= Attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(backoff(attempt - 1))
+		}
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = &NetworkError{Err: err}
+			continue
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			if attempt < Attempts {
+				_ = resp.Body.Close()
+				continue
+			}
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// NetworkError means the server could not be reached at all, as opposed to
+// the server answering with an error status.
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string { return fmt.Sprintf("error making request: %v", e.Err) }
+func (e *NetworkError) Unwrap() error { return e.Err }
@@ -4,31 +4,69 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/goedit"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/gomod"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
 )
 
-// StripIntegration removes all GF_<integration>_START/END blocks from all files in the project
-func StripIntegration(projectPath string, integration string) error {
-	startMarker := fmt.Sprintf("// GF_%s_START", integration)
-	endMarker := fmt.Sprintf("// GF_%s_END", integration)
-	sqlStartMarker := fmt.Sprintf("-- GF_%s_START", integration)
-	sqlEndMarker := fmt.Sprintf("-- GF_%s_END", integration)
+// Names maps each integration to the name used in its GF_<NAME>_START/END markers.
+var Names = map[string]string{
+	"stripe":   "STRIPE",
+	"s3":       "FILE",
+	"postmark": "EMAIL",
+}
+
+// Domains maps each integration to its package under app/service-core/domain
+// and app/service-core/transport.
+var Domains = map[string]string{
+	"stripe":   "payment",
+	"s3":       "file",
+	"postmark": "email",
+}
+
+// Migrations maps each integration to the suffix of its migration file.
+var Migrations = map[string]string{
+	"stripe":   "create_subscriptions.sql",
+	"s3":       "create_files.sql",
+	"postmark": "create_emails.sql",
+}
+
+// downloadTemplate downloads the template version to dst with its Go code
+// moved to the project's module path, so the files copied from it import the
+// project's packages.
+func downloadTemplate(email, apiKey, version, module, dst string) error {
+	if _, err := repo.DownloadRepo(email, apiKey, version, dst); err != nil {
+		return err
+	}
+	if module != config.DefaultModule {
+		return gomod.Rewrite(dst, config.DefaultModule, module)
+	}
+	return nil
+}
 
+// StripIntegration removes all GF_<integration>_START/END blocks from every file
+// in the project whose comment style the markers package understands.
+func StripIntegration(projectPath string, integration string) error {
 	return filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if info.IsDir() {
+			if markers.SkipDir(info.Name()) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		ext := filepath.Ext(path)
-		if ext != ".go" && ext != ".sql" {
+		styles := markers.StylesFor(path)
+		if styles == nil {
 			return nil
 		}
 
@@ -37,60 +75,17 @@ func StripIntegration(projectPath string, integration string) error {
 			return err
 		}
 
-		s := string(content)
-		original := s
-
-		// Use appropriate markers based on file type
-		if ext == ".sql" {
-			s = RemoveMarkerBlocks(s, sqlStartMarker, sqlEndMarker)
-		} else {
-			s = RemoveMarkerBlocks(s, startMarker, endMarker)
+		s, n, err := markers.Remove(string(content), styles, integration)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
-
-		// Only write if changed
-		if s != original {
-			if err := os.WriteFile(path, []byte(s), 0644); err != nil {
-				return err
-			}
+		if n == 0 {
+			return nil
 		}
-
-		return nil
+		return os.WriteFile(path, []byte(s), info.Mode().Perm())
 	})
 }
 
-// RemoveMarkerBlocks removes all blocks between startMarker and endMarker (inclusive of markers)
-func RemoveMarkerBlocks(content, startMarker, endMarker string) string {
-	for {
-		startIdx := strings.Index(content, startMarker)
-		if startIdx == -1 {
-			break
-		}
-
-		// Find start of line containing start marker
-		lineStart := strings.LastIndex(content[:startIdx], "\n")
-		if lineStart == -1 {
-			lineStart = 0
-		} else {
-			lineStart++ // Move past the newline
-		}
-
-		// Find end marker
-		endIdx := strings.Index(content[startIdx:], endMarker)
-		if endIdx == -1 {
-			break
-		}
-		endIdx = startIdx + endIdx + len(endMarker)
-
-		// Skip to end of line
-		if endIdx < len(content) && content[endIdx] == '\n' {
-			endIdx++
-		}
-
-		content = content[:lineStart] + content[endIdx:]
-	}
-	return content
-}
-
 // CopyDir copies a directory recursively
 func CopyDir(src, dst string) error {
 	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
@@ -178,6 +173,9 @@ func copyMarkedFiles(srcDir, dstDir, keepIntegration string) error {
 			return nil
 		}
 
+		// Only Go and SQL files are copied: other files that mention a marker
+		// (compose, env, Dockerfiles, docs) may carry user edits that a
+		// wholesale copy would wipe.
 		ext := filepath.Ext(srcPath)
 		if ext != ".go" && ext != ".sql" {
 			return nil
@@ -221,58 +219,31 @@ func copyMarkedFiles(srcDir, dstDir, keepIntegration string) error {
 		}
 
 		// Strip other integrations' markers (not the one we're adding)
-		s := string(content)
-		s = StripOtherIntegrations(s, keepIntegration)
+		s, err := StripOtherIntegrations(srcPath, string(content), keepIntegration)
+		if err != nil {
+			return err
+		}
 
 		return os.WriteFile(dstPath, []byte(s), 0644)
 	})
 }
 
-// AppendMarkerBlock extracts the marker block from src and appends it to dst
+// AppendMarkerBlock extracts the marker blocks from src and appends them to dst
 func AppendMarkerBlock(srcPath, dstPath, integration string) error {
 	srcContent, err := os.ReadFile(srcPath)
 	if err != nil {
 		return err
 	}
 
-	// Determine marker style based on file extension
-	var startMarker, endMarker string
-	if filepath.Ext(srcPath) == ".sql" {
-		startMarker = fmt.Sprintf("-- GF_%s_START", integration)
-		endMarker = fmt.Sprintf("-- GF_%s_END", integration)
-	} else {
-		startMarker = fmt.Sprintf("// GF_%s_START", integration)
-		endMarker = fmt.Sprintf("// GF_%s_END", integration)
+	styles := markers.StylesFor(srcPath)
+	blocks, err := markers.Extract(string(srcContent), styles, integration)
+	if err != nil {
+		return fmt.Errorf("%s: %w", srcPath, err)
 	}
-
-	s := string(srcContent)
-	startIdx := strings.Index(s, startMarker)
-	if startIdx == -1 {
+	if len(blocks) == 0 {
 		return nil // No marker block to append
 	}
 
-	// Find start of line containing start marker
-	lineStart := strings.LastIndex(s[:startIdx], "\n")
-	if lineStart == -1 {
-		lineStart = 0
-	} else {
-		lineStart++ // Move past the newline
-	}
-
-	// Find end marker
-	endIdx := strings.Index(s[startIdx:], endMarker)
-	if endIdx == -1 {
-		return nil // Malformed markers
-	}
-	endIdx = startIdx + endIdx + len(endMarker)
-
-	// Include the newline after end marker if present
-	if endIdx < len(s) && s[endIdx] == '\n' {
-		endIdx++
-	}
-
-	markerBlock := s[lineStart:endIdx]
-
 	// Read existing destination file
 	dstContent, err := os.ReadFile(dstPath)
 	if err != nil {
@@ -280,16 +251,20 @@ func AppendMarkerBlock(srcPath, dstPath, integration string) error {
 	}
 
 	// Check if marker block already exists
-	if strings.Contains(string(dstContent), startMarker) {
+	existing, err := markers.Regions(string(dstContent), styles, integration)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dstPath, err)
+	}
+	if len(existing) > 0 {
 		return nil // Already has this integration
 	}
 
-	// Append the marker block
+	// Append the marker blocks
 	result := string(dstContent)
 	if !strings.HasSuffix(result, "\n") {
 		result += "\n"
 	}
-	result += markerBlock
+	result += strings.Join(blocks, "")
 
 	return os.WriteFile(dstPath, []byte(result), 0644)
 }
@@ -300,7 +275,7 @@ func StripClientIntegration(clientType, clientPath, integration string) error {
 		return fmt.Errorf("unknown client type %q", clientType)
 	}
 
-	routeSubpath, err := integrationRouteSubpath(spec, integration)
+	routeSubpath, err := RouteSubpath(spec, integration)
 	if err != nil {
 		return err
 	}
@@ -318,7 +293,7 @@ func AddClientIntegration(tmpProject, clientType, clientPath, integration string
 		return fmt.Errorf("unknown client type %q", clientType)
 	}
 
-	routeSubpath, err := integrationRouteSubpath(spec, integration)
+	routeSubpath, err := RouteSubpath(spec, integration)
 	if err != nil {
 		return err
 	}
@@ -342,7 +317,9 @@ func AddClientIntegration(tmpProject, clientType, clientPath, integration string
 	return nil
 }
 
-func integrationRouteSubpath(spec clients.Spec, integration string) (string, error) {
+// RouteSubpath returns the client route (file or directory, relative to the
+// client app) an integration ships for spec.
+func RouteSubpath(spec clients.Spec, integration string) (string, error) {
 	switch integration {
 	case "stripe":
 		return spec.PaymentsRouteSubpath, nil
@@ -355,240 +332,157 @@ func integrationRouteSubpath(spec clients.Spec, integration string) (string, err
 	}
 }
 
-// MergeMainGoMarkers extracts marker blocks from src main.go and injects them into dst main.go
-// Import blocks are injected before GF_MAIN_IMPORT_SERVICES_START
-// Init blocks are injected before GF_MAIN_INIT_SERVICES_START
+var goStyles = []markers.Style{markers.Slash}
+
+// MergeMainGoMarkers extracts GF_<integration> blocks from src main.go and merges
+// them into dst main.go on the syntax tree. Where a block lives (import
+// declaration or function body) is decided from the source AST, and the merge
+// point in dst is resolved on its AST, preferring the GF_MAIN_* markers and
+// falling back to the statement that follows the block in src.
 func MergeMainGoMarkers(srcPath, dstPath, integration string) error {
-	srcContent, err := os.ReadFile(srcPath)
+	dstContent, err := os.ReadFile(dstPath)
 	if err != nil {
 		return err
 	}
-
-	dstContent, err := os.ReadFile(dstPath)
+	existing, err := markers.Regions(string(dstContent), goStyles, integration)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s: %w", dstPath, err)
 	}
-
-	startMarker := fmt.Sprintf("// GF_%s_START", integration)
-	endMarker := fmt.Sprintf("// GF_%s_END", integration)
-
-	// Check if already has this integration
-	if strings.Contains(string(dstContent), startMarker) {
+	if len(existing) > 0 {
 		return nil // Already has this integration
 	}
 
-	src := string(srcContent)
-	dst := string(dstContent)
-
-	// Extract all marker blocks from source
-	var importBlocks, initBlocks []string
-	remaining := src
-	for {
-		startIdx := strings.Index(remaining, startMarker)
-		if startIdx == -1 {
-			break
-		}
-
-		// Find start of line
-		lineStart := strings.LastIndex(remaining[:startIdx], "\n")
-		if lineStart == -1 {
-			lineStart = 0
-		} else {
-			lineStart++
-		}
-
-		// Find end marker
-		endIdx := strings.Index(remaining[startIdx:], endMarker)
-		if endIdx == -1 {
-			break
-		}
-		endIdx = startIdx + endIdx + len(endMarker)
-
-		// Include newline after end marker
-		if endIdx < len(remaining) && remaining[endIdx] == '\n' {
-			endIdx++
-		}
-
-		block := remaining[lineStart:endIdx]
-
-		// Determine if this is an import block (contains import paths like "gofast/")
-		if strings.Contains(block, "\"gofast/") || strings.Contains(block, "Svc \"") || strings.Contains(block, "Route \"") {
-			importBlocks = append(importBlocks, block)
-		} else {
-			initBlocks = append(initBlocks, block)
-		}
-
-		remaining = remaining[endIdx:]
+	src, err := goedit.ParseFile(srcPath)
+	if err != nil {
+		return err
 	}
-
-	// Inject import blocks before GF_MAIN_IMPORT_SERVICES_START
-	if len(importBlocks) > 0 {
-		importMarker := "// GF_MAIN_IMPORT_SERVICES_START"
-		idx := strings.Index(dst, importMarker)
-		if idx != -1 {
-			// Find start of line
-			lineStart := strings.LastIndex(dst[:idx], "\n")
-			if lineStart == -1 {
-				lineStart = 0
-			} else {
-				lineStart++
-			}
-			insertContent := strings.Join(importBlocks, "")
-			dst = dst[:lineStart] + insertContent + dst[lineStart:]
-		}
+	dst, err := goedit.Parse(dstPath, dstContent)
+	if err != nil {
+		return err
 	}
 
-	// Inject init blocks before GF_MAIN_INIT_SERVICES_START
-	if len(initBlocks) > 0 {
-		initMarker := "// GF_MAIN_INIT_SERVICES_START"
-		idx := strings.Index(dst, initMarker)
-		if idx != -1 {
-			// Find start of line
-			lineStart := strings.LastIndex(dst[:idx], "\n")
-			if lineStart == -1 {
-				lineStart = 0
-			} else {
-				lineStart++
+	blocks, err := markers.Regions(string(src.Source()), goStyles, integration)
+	if err != nil {
+		return fmt.Errorf("%s: %w", srcPath, err)
+	}
+	for _, block := range blocks {
+		text := string(src.Source()[block.From:block.To])
+		where := src.EnclosingAt(block.To - 1)
+		what := fmt.Sprintf("GF_%s block from %s", integration, src.PositionAt(block.From))
+		switch where.Kind {
+		case "import":
+			scope, err := dst.ImportScope()
+			if err != nil {
+				return err
 			}
-			// Add newline before if needed
-			insertContent := strings.Join(initBlocks, "")
-			if !strings.HasPrefix(insertContent, "\n") {
-				insertContent = "\n" + insertContent
+			if err := dst.Insert(scope, text, what,
+				goedit.BeforeComment("GF_MAIN_IMPORT_SERVICES_START"),
+				goedit.AtEnd(),
+			); err != nil {
+				return err
 			}
-			dst = dst[:lineStart] + insertContent + dst[lineStart:]
+		case "func":
+			scope, err := dst.FuncScope(where.Name)
+			if err != nil {
+				return err
+			}
+			anchors := []goedit.Anchor{goedit.BeforeComment("GF_MAIN_INIT_SERVICES_START")}
+			if where.Next != nil {
+				anchors = append(anchors, goedit.BeforeNode(goedit.SameText(src.Text(where.Next))))
+			}
+			if err := dst.Insert(scope, "\n"+text, what, anchors...); err != nil {
+				return err
+			}
+		default:
+			return &goedit.MergeError{Pos: src.PositionAt(block.From), Msg: fmt.Sprintf("GF_%s block is not inside the import declaration or a function body", integration)}
 		}
 	}
 
-	return os.WriteFile(dstPath, []byte(dst), 0644)
+	return dst.WriteFile(dstPath)
 }
 
-// MergeConfigMarkers extracts marker blocks from src config.go and injects them into dst config.go
-// Blocks are inserted at GF_CONFIG_STRUCT_INSERT and GF_CONFIG_INIT_INSERT markers
+// MergeConfigMarkers extracts GF_<integration> blocks from src config.go and
+// merges them into dst config.go on the syntax tree. Blocks inside a struct type
+// become fields of the same struct in dst (after GF_CONFIG_STRUCT_INSERT when
+// present); blocks inside a function body are inserted into the same function
+// (after GF_CONFIG_INIT_INSERT when present, otherwise before its return).
 func MergeConfigMarkers(srcPath, dstPath, integration string) error {
-	srcContent, err := os.ReadFile(srcPath)
+	dstContent, err := os.ReadFile(dstPath)
 	if err != nil {
 		return err
 	}
-
-	dstContent, err := os.ReadFile(dstPath)
+	existing, err := markers.Regions(string(dstContent), goStyles, integration)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s: %w", dstPath, err)
 	}
-
-	startMarker := fmt.Sprintf("// GF_%s_START", integration)
-	endMarker := fmt.Sprintf("// GF_%s_END", integration)
-
-	// Check if already has this integration
-	if strings.Contains(string(dstContent), startMarker) {
+	if len(existing) > 0 {
 		return nil // Already has this integration
 	}
 
-	src := string(srcContent)
-	dst := string(dstContent)
-
-	// Extract all marker blocks from source, categorized by type
-	var structBlocks, initBlocks []string
-	remaining := src
-	for {
-		startIdx := strings.Index(remaining, startMarker)
-		if startIdx == -1 {
-			break
-		}
-
-		// Find start of line
-		lineStart := strings.LastIndex(remaining[:startIdx], "\n")
-		if lineStart == -1 {
-			lineStart = 0
-		} else {
-			lineStart++
-		}
-
-		// Find end marker
-		endIdx := strings.Index(remaining[startIdx:], endMarker)
-		if endIdx == -1 {
-			break
-		}
-		endIdx = startIdx + endIdx + len(endMarker)
-
-		// Include newline after end marker
-		if endIdx < len(remaining) && remaining[endIdx] == '\n' {
-			endIdx++
-		}
-
-		block := remaining[lineStart:endIdx]
-
-		// Categorize: blocks with MustSetEnv are initialization, others are struct fields
-		if strings.Contains(block, "MustSetEnv") {
-			initBlocks = append(initBlocks, block)
-		} else {
-			structBlocks = append(structBlocks, block)
-		}
-
-		remaining = remaining[endIdx:]
+	src, err := goedit.ParseFile(srcPath)
+	if err != nil {
+		return err
 	}
-
-	// Insert struct field blocks after GF_CONFIG_STRUCT_INSERT marker
-	structInsertMarker := "// GF_CONFIG_STRUCT_INSERT"
-	for _, block := range structBlocks {
-		idx := strings.Index(dst, structInsertMarker)
-		if idx != -1 {
-			// Find end of the marker line
-			lineEnd := strings.Index(dst[idx:], "\n")
-			if lineEnd != -1 {
-				insertPoint := idx + lineEnd + 1
-				dst = dst[:insertPoint] + "\n" + block + dst[insertPoint:]
-			}
-		}
+	dst, err := goedit.Parse(dstPath, dstContent)
+	if err != nil {
+		return err
 	}
 
-	// Insert initialization blocks after GF_CONFIG_INIT_INSERT marker
-	initInsertMarker := "// GF_CONFIG_INIT_INSERT"
-	for _, block := range initBlocks {
-		idx := strings.Index(dst, initInsertMarker)
-		if idx != -1 {
-			// Find end of the marker line
-			lineEnd := strings.Index(dst[idx:], "\n")
-			if lineEnd != -1 {
-				insertPoint := idx + lineEnd + 1
-				dst = dst[:insertPoint] + block + dst[insertPoint:]
+	blocks, err := markers.Regions(string(src.Source()), goStyles, integration)
+	if err != nil {
+		return fmt.Errorf("%s: %w", srcPath, err)
+	}
+	for _, block := range blocks {
+		text := string(src.Source()[block.From:block.To])
+		where := src.EnclosingAt(block.To - 1)
+		what := fmt.Sprintf("GF_%s block from %s", integration, src.PositionAt(block.From))
+		switch where.Kind {
+		case "struct":
+			scope, err := dst.StructScope(where.Name)
+			if err != nil {
+				return err
+			}
+			if err := dst.Insert(scope, "\n"+text, what,
+				goedit.AfterComment("GF_CONFIG_STRUCT_INSERT"),
+				goedit.AtEnd(),
+			); err != nil {
+				return err
+			}
+		case "func":
+			scope, err := dst.FuncScope(where.Name)
+			if err != nil {
+				return err
+			}
+			if err := dst.Insert(scope, text, what,
+				goedit.AfterComment("GF_CONFIG_INIT_INSERT"),
+				goedit.BeforeNode(goedit.IsReturn),
+				goedit.AtEnd(),
+			); err != nil {
+				return err
 			}
+		default:
+			return &goedit.MergeError{Pos: src.PositionAt(block.From), Msg: fmt.Sprintf("GF_%s block is not inside a struct type or a function body", integration)}
 		}
 	}
 
-	return os.WriteFile(dstPath, []byte(dst), 0644)
+	return dst.WriteFile(dstPath)
 }
 
-// StripOtherIntegrations removes marker blocks for all integrations except the specified one
-func StripOtherIntegrations(content, keepIntegration string) string {
-	// Find all integration markers in the content
-	re := regexp.MustCompile(`// GF_([A-Z]+)_START`)
-	matches := re.FindAllStringSubmatch(content, -1)
-
-	seen := make(map[string]bool)
-	for _, match := range matches {
-		if len(match) > 1 {
-			seen[match[1]] = true
+// StripOtherIntegrations removes marker blocks for all integrations except the
+// specified one. path selects the comment styles to recognise.
+func StripOtherIntegrations(path, content, keepIntegration string) (string, error) {
+	styles := markers.StylesFor(path)
+	for _, name := range Names {
+		if name == keepIntegration {
+			continue
 		}
-	}
-
-	// Also check for SQL-style markers
-	reSql := regexp.MustCompile(`-- GF_([A-Z]+)_START`)
-	matchesSql := reSql.FindAllStringSubmatch(content, -1)
-	for _, match := range matchesSql {
-		if len(match) > 1 {
-			seen[match[1]] = true
-		}
-	}
-
-	// Strip all integrations except the one we're keeping
-	for integration := range seen {
-		if integration != keepIntegration {
-			content = RemoveMarkerBlocks(content, fmt.Sprintf("// GF_%s_START", integration), fmt.Sprintf("// GF_%s_END", integration))
-			content = RemoveMarkerBlocks(content, fmt.Sprintf("-- GF_%s_START", integration), fmt.Sprintf("-- GF_%s_END", integration))
+		var err error
+		content, _, err = markers.Remove(content, styles, name)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
 		}
 	}
-
-	return content
+	return content, nil
 }
 
 // AddMigration copies a migration file with the next available number
@@ -7,7 +7,6 @@ import (
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
-	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
 )
 
 // PostmarkStrip removes all email/Postmark-related code from a freshly initialized project.
@@ -47,8 +46,8 @@ func PostmarkAddClient(tmpProject, clientType, clientPath string) error {
 }
 
 // PostmarkAdd adds Postmark email integration to an existing project.
-// Called by 'gof add postmark' command.
-func PostmarkAdd(email, apiKey string) error {
+// Called by 'gof add postmark' command with the project's pinned template version.
+func PostmarkAdd(email, apiKey, version string, cfg *config.Config) error {
 	// 1. Download template to temp location
 	tmpDir, err := os.MkdirTemp("", "gofast-email-*")
 	if err != nil {
@@ -56,27 +55,11 @@ func PostmarkAdd(email, apiKey string) error {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
-	// Save current directory and chdir to tmpDir for download
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("getting current dir: %w", err)
-	}
-	if err := os.Chdir(tmpDir); err != nil {
-		return fmt.Errorf("changing to temp dir: %w", err)
-	}
-
-	if err := repo.DownloadRepo(email, apiKey, "template"); err != nil {
-		_ = os.Chdir(cwd)
+	tmpProject := filepath.Join(tmpDir, "template")
+	if err := downloadTemplate(email, apiKey, version, cfg.GoModule(), tmpProject); err != nil {
 		return fmt.Errorf("downloading template: %w", err)
 	}
 
-	// Return to original directory
-	if err := os.Chdir(cwd); err != nil {
-		return fmt.Errorf("returning to original dir: %w", err)
-	}
-
-	tmpProject := filepath.Join(tmpDir, "template")
-
 	// 2. Copy email domain folder
 	srcDomain := filepath.Join(tmpProject, "app", "service-core", "domain", "email")
 	dstDomain := filepath.Join("app", "service-core", "domain", "email")
@@ -101,11 +84,6 @@ func PostmarkAdd(email, apiKey string) error {
 		return fmt.Errorf("copying files with EMAIL markers: %w", err)
 	}
 
-	cfg, err := config.ParseConfig()
-	if err != nil {
-		return fmt.Errorf("parsing config: %w", err)
-	}
-
 	enabledClients := clients.Enabled(cfg)
 	for _, client := range enabledClients {
 		clientPath := filepath.Join("app", client.ServiceDir)
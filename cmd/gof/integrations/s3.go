@@ -7,7 +7,6 @@ import (
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/clients"
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
-	"github.com/gofast-live/gofast-cli/v2/cmd/gof/repo"
 )
 
 // S3Strip removes all S3/files-related code from a freshly initialized project.
@@ -47,8 +46,8 @@ func S3AddClient(tmpProject, clientType, clientPath string) error {
 }
 
 // S3Add adds S3 file storage integration to an existing project.
-// Called by 'gof add s3' command.
-func S3Add(email, apiKey string) error {
+// Called by 'gof add s3' command with the project's pinned template version.
+func S3Add(email, apiKey, version string, cfg *config.Config) error {
 	// 1. Download template to temp location
 	tmpDir, err := os.MkdirTemp("", "gofast-files-*")
 	if err != nil {
@@ -56,27 +55,11 @@ func S3Add(email, apiKey string) error {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
-	// Save current directory and chdir to tmpDir for download
-	cwd, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("getting current dir: %w", err)
-	}
-	if err := os.Chdir(tmpDir); err != nil {
-		return fmt.Errorf("changing to temp dir: %w", err)
-	}
-
-	if err := repo.DownloadRepo(email, apiKey, "template"); err != nil {
-		_ = os.Chdir(cwd)
+	tmpProject := filepath.Join(tmpDir, "template")
+	if err := downloadTemplate(email, apiKey, version, cfg.GoModule(), tmpProject); err != nil {
 		return fmt.Errorf("downloading template: %w", err)
 	}
 
-	// Return to original directory
-	if err := os.Chdir(cwd); err != nil {
-		return fmt.Errorf("returning to original dir: %w", err)
-	}
-
-	tmpProject := filepath.Join(tmpDir, "template")
-
 	// 2. Copy file domain folder
 	srcDomain := filepath.Join(tmpProject, "app", "service-core", "domain", "file")
 	dstDomain := filepath.Join("app", "service-core", "domain", "file")
@@ -101,11 +84,6 @@ func S3Add(email, apiKey string) error {
 		return fmt.Errorf("copying files with FILE markers: %w", err)
 	}
 
-	cfg, err := config.ParseConfig()
-	if err != nil {
-		return fmt.Errorf("parsing config: %w", err)
-	}
-
 	enabledClients := clients.Enabled(cfg)
 	for _, client := range enabledClients {
 		clientPath := filepath.Join("app", client.ServiceDir)
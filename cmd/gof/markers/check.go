@@ -0,0 +1,596 @@
+package markers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity says whether a problem breaks generation or only loses a preferred
+// merge point.
+type Severity int
+
+const (
+	// Warning problems have a fallback; generation still works.
+	Warning Severity = iota
+	// Failure problems make `gof model` or `gof add` fail or generate wrong code.
+	Failure
+)
+
+func (s Severity) String() string {
+	if s == Failure {
+		return "error"
+	}
+	return "warning"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Rule lists the marker tokens the CLI relies on in one project file.
+type Rule struct {
+	Path     string   // slash-separated, relative to the project root
+	Tokens   []string // full tokens, e.g. GF_MAIN_MOUNT_ROUTES_END
+	Repeat   bool     // tokens may appear more than once; order is not checked
+	Optional bool     // the file only exists in some projects (e.g. per client)
+	Service  bool     // the file is in every Go service; Path is service-core's
+	Severity Severity
+}
+
+// Rules is the registry of markers generation depends on, for a project with
+// only service-core. Tokens of a rule without Repeat must appear exactly once
+// and in the listed order. RulesFor adapts it to other Go services.
+var Rules = []Rule{
+	{
+		Path: "app/service-core/main.go",
+		Tokens: []string{
+			"GF_MAIN_IMPORT_SERVICES_START", "GF_MAIN_IMPORT_SERVICES_END",
+			"GF_MAIN_IMPORT_ROUTES_START", "GF_MAIN_IMPORT_ROUTES_END",
+			"GF_MAIN_INIT_SERVICES_START", "GF_MAIN_INIT_SERVICES_END",
+			"GF_MAIN_MOUNT_ROUTES_START", "GF_MAIN_MOUNT_ROUTES_END",
+		},
+		Service:  true,
+		Severity: Warning,
+	},
+	{
+		Path:     "app/service-core/config/config.go",
+		Tokens:   []string{"GF_CONFIG_STRUCT_INSERT", "GF_CONFIG_INIT_INSERT"},
+		Severity: Warning,
+	},
+	{
+		Path:     "app/pkg/auth/auth.go",
+		Tokens:   []string{"GF_ACCESS_FLAGS_END", "GF_USER_ACCESS_END"},
+		Severity: Failure,
+	},
+	{
+		Path: "app/service-core/domain/skeleton/service_test.go",
+		Tokens: []string{
+			"GF_TP_TEST_ENTITY_FIELDS_START", "GF_TP_TEST_ENTITY_FIELDS_END",
+			"GF_TP_TEST_CREATE_FIELDS_START", "GF_TP_TEST_CREATE_FIELDS_END",
+			"GF_TP_TEST_EDIT_FIELDS_START", "GF_TP_TEST_EDIT_FIELDS_END",
+			"GF_TP_TEST_INVALID_FIELDS_START", "GF_TP_TEST_INVALID_FIELDS_END",
+		},
+		Repeat:   true,
+		Service:  true,
+		Severity: Warning,
+	},
+	{
+		Path:     "app/service-core/domain/skeleton/validation_test.go",
+		Tokens:   []string{"GF_FIXTURES_START", "GF_FIXTURES_END"},
+		Service:  true,
+		Severity: Warning,
+	},
+	{
+		Path: "app/service-core/transport/skeleton/route_test.go",
+		Tokens: []string{
+			"GF_TP_TEST_ENTITY_FIELDS_START", "GF_TP_TEST_ENTITY_FIELDS_END",
+			"GF_TP_TEST_CREATE_FIELDS_START", "GF_TP_TEST_CREATE_FIELDS_END",
+			"GF_TP_TEST_EDIT_FIELDS_START", "GF_TP_TEST_EDIT_FIELDS_END",
+			"GF_TP_TEST_EDIT_ASSERT_START", "GF_TP_TEST_EDIT_ASSERT_END",
+		},
+		Repeat:   true,
+		Service:  true,
+		Severity: Warning,
+	},
+	{
+		Path:     "e2e/skeletons.test.ts",
+		Tokens:   []string{"GF_MODEL_CONFIG_START", "GF_MODEL_CONFIG_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-svelte/src/routes/(app)/models/skeletons/+page.svelte",
+		Tokens:   []string{"GF_LIST_HEADERS_START", "GF_LIST_HEADERS_END", "GF_LIST_CELLS_START", "GF_LIST_CELLS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-svelte/src/routes/(app)/models/skeletons/[skeleton_id]/+page.svelte",
+		Tokens:   detailTokens,
+		Repeat:   true,
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-tanstack/src/routes/_layout/models/skeletons/index.tsx",
+		Tokens:   []string{"GF_LIST_HEADERS_START", "GF_LIST_HEADERS_END", "GF_LIST_CELLS_START", "GF_LIST_CELLS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-tanstack/src/routes/_layout/models/skeletons/$skeleton_id.tsx",
+		Tokens:   detailTokens,
+		Repeat:   true,
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-next/src/app/(app)/models/skeletons/page.tsx",
+		Tokens:   []string{"GF_LIST_HEADERS_START", "GF_LIST_HEADERS_END", "GF_LIST_CELLS_START", "GF_LIST_CELLS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-next/src/app/(app)/models/skeletons/[skeleton_id]/page.tsx",
+		Tokens:   detailTokens,
+		Repeat:   true,
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-vue/app/pages/models/skeletons/index.vue",
+		Tokens:   []string{"GF_LIST_HEADERS_START", "GF_LIST_HEADERS_END", "GF_LIST_CELLS_START", "GF_LIST_CELLS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-vue/app/pages/models/skeletons/[skeleton_id].vue",
+		Tokens:   detailTokens,
+		Repeat:   true,
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-htmx/main.go",
+		Tokens:   []string{"GF_MAIN_MOUNT_ROUTES_START", "GF_MAIN_MOUNT_ROUTES_END"},
+		Optional: true,
+		Severity: Warning,
+	},
+	{
+		Path:     "app/service-htmx/handlers/skeleton.go",
+		Tokens:   []string{"GF_DETAIL_FORMDATA_START", "GF_DETAIL_FORMDATA_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-htmx/templates/models/skeletons/list.html",
+		Tokens:   []string{"GF_LIST_HEADERS_START", "GF_LIST_HEADERS_END", "GF_LIST_CELLS_START", "GF_LIST_CELLS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+	{
+		Path:     "app/service-htmx/templates/models/skeletons/detail.html",
+		Tokens:   []string{"GF_DETAIL_FIELDS_START", "GF_DETAIL_FIELDS_END"},
+		Optional: true,
+		Severity: Failure,
+	},
+}
+
+var detailTokens = []string{
+	"GF_DETAIL_EMPTY_START", "GF_DETAIL_EMPTY_END",
+	"GF_DETAIL_FORMDATA_START", "GF_DETAIL_FORMDATA_END",
+	"GF_DETAIL_CREATE_FIELDS_START", "GF_DETAIL_CREATE_FIELDS_END",
+	"GF_DETAIL_EDIT_FIELDS_START", "GF_DETAIL_EDIT_FIELDS_END",
+	"GF_DETAIL_FIELDS_START", "GF_DETAIL_FIELDS_END",
+}
+
+// TemplateFiles are template files generation reads that carry no markers of
+// their own. A template must ship them next to every file in Rules.
+var TemplateFiles = []string{
+	"app/service-core/domain/login/service.go",
+	"app/service-core/domain/skeleton/service.go",
+	"app/service-core/domain/skeleton/validation.go",
+	"app/service-core/transport/skeleton/route.go",
+	"app/service-core/storage/query.sql",
+	"proto/v1/main.proto",
+	"scripts/seed_dev_user.sh",
+}
+
+// RulesFor returns Rules with the per-service rules of the Go services in
+// pkgs (directories below app/, e.g. service-billing) in place of
+// service-core's. `gof model --service billing` edits the main.go and
+// generates from the skeleton tests of service-billing, so only those have to
+// be checked.
+func RulesFor(pkgs ...string) []Rule {
+	var rules []Rule
+	for _, rule := range Rules {
+		if !rule.Service {
+			rules = append(rules, rule)
+			continue
+		}
+		for _, pkg := range pkgs {
+			r := rule
+			r.Path = "app/" + pkg + strings.TrimPrefix(rule.Path, "app/service-core")
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// Problem is a single marker issue found by Check.
+type Problem struct {
+	Path     string   `json:"path"` // slash-separated, relative to the project root
+	Line     int      `json:"line"` // 1-based, 0 when the problem has no location (missing marker)
+	Token    string   `json:"token"`
+	Msg      string   `json:"message"`
+	Severity Severity `json:"severity"`
+	Fixable  bool     `json:"fixable"`
+}
+
+func (p Problem) String() string {
+	loc := p.Path
+	if p.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", p.Path, p.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", loc, p.Severity, p.Token, p.Msg)
+}
+
+// HasFailures reports whether any problem breaks generation.
+func HasFailures(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == Failure {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipDir reports whether a directory is never scanned for markers.
+func SkipDir(name string) bool {
+	switch name {
+	case "node_modules", ".git", ".svelte-kit", ".next", ".nuxt", ".output", "dist":
+		return true
+	}
+	return false
+}
+
+// Check scans the project at root for every marker in rules and for
+// unbalanced regions in any supported file. Generated files are ignored.
+func Check(root string, rules []Rule) ([]Problem, error) {
+	var problems []Problem
+	for _, rule := range rules {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rule.Path)))
+		if os.IsNotExist(err) {
+			if !rule.Optional {
+				problems = append(problems, Problem{Path: rule.Path, Token: "-", Msg: "file not found", Severity: rule.Severity})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, checkRule(rule, string(content))...)
+	}
+
+	err := walk(root, func(rel string, content string) error {
+		problems = append(problems, checkBalance(rel, content, rules)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return a.Line - b.Line
+	})
+	return problems, nil
+}
+
+// CheckTemplate validates a template tree before anything is generated from
+// it. Unlike Check it requires every file in Rules, including the optional
+// per-client ones, plus TemplateFiles, and reports missing files as failures.
+// The one exception is a client the template does not ship at all (its
+// app/service-<client> directory is missing): older templates predate some
+// clients, and adding such a client fails on its own.
+func CheckTemplate(root string) ([]Problem, error) {
+	var problems []Problem
+	for _, path := range TemplateFiles {
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(path))); err != nil {
+			problems = append(problems, Problem{Path: path, Token: "-", Msg: "file not found", Severity: Failure})
+		}
+	}
+	for _, rule := range Rules {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rule.Path)))
+		if os.IsNotExist(err) {
+			if rule.Optional && !serviceDirExists(root, rule.Path) {
+				continue
+			}
+			problems = append(problems, Problem{Path: rule.Path, Token: "-", Msg: "file not found", Severity: Failure})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, checkRule(rule, string(content))...)
+		problems = append(problems, checkBalance(rule.Path, string(content), Rules)...)
+	}
+	return problems, nil
+}
+
+// serviceDirExists reports whether the app/service-* directory path is in
+// exists below root. Paths outside such a directory always count as present.
+func serviceDirExists(root, path string) bool {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 3 || parts[0] != "app" || !strings.HasPrefix(parts[1], "service-") {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(root, parts[0], parts[1]))
+	return err == nil
+}
+
+func checkRule(rule Rule, content string) []Problem {
+	styles := StylesFor(rule.Path)
+	found := make(map[string][]Marker)
+	for _, m := range Scan(content, styles) {
+		found[m.Token] = append(found[m.Token], m)
+	}
+
+	var problems []Problem
+	for _, token := range rule.Tokens {
+		ms := found[token]
+		switch {
+		case len(ms) == 0:
+			problems = append(problems, Problem{
+				Path:     rule.Path,
+				Token:    token,
+				Msg:      "marker is missing",
+				Severity: rule.Severity,
+				Fixable:  pairFor(token, found) != nil,
+			})
+		case len(ms) > 1 && !rule.Repeat:
+			for i, m := range ms[1:] {
+				if onlyBlankBetween(content, ms[i], m) {
+					continue // adjacent copies are reported (and fixed) by checkBalance
+				}
+				problems = append(problems, Problem{
+					Path:     rule.Path,
+					Line:     m.Line + 1,
+					Token:    token,
+					Msg:      fmt.Sprintf("duplicate marker, first at line %d", ms[0].Line+1),
+					Severity: rule.Severity,
+				})
+			}
+		}
+	}
+	if rule.Repeat {
+		return problems
+	}
+
+	prev := ""
+	for _, token := range rule.Tokens {
+		if len(found[token]) != 1 {
+			continue
+		}
+		if prev != "" && found[token][0].Line < found[prev][0].Line {
+			problems = append(problems, Problem{
+				Path:     rule.Path,
+				Line:     found[token][0].Line + 1,
+				Token:    token,
+				Msg:      fmt.Sprintf("marker must come after %s (line %d)", prev, found[prev][0].Line+1),
+				Severity: rule.Severity,
+			})
+		}
+		prev = token
+	}
+	return problems
+}
+
+func checkBalance(rel, content string, rules []Rule) []Problem {
+	styles := StylesFor(rel)
+	lines := strings.Split(content, "\n")
+	var problems []Problem
+	scanned := Scan(content, styles)
+	present := make(map[string]bool)
+	for _, m := range scanned {
+		present[m.Token] = true
+	}
+	anchors := standalone(rel, rules)
+	var prev Marker
+	for i, m := range scanned {
+		if anchors[m.Token] && !present[counterpart(m.Token)] {
+			lines[m.Line] = "" // used as a lone insertion point, not a region
+		}
+		if i > 0 && prev.Token == m.Token && onlyBlankBetween(content, prev, m) {
+			problems = append(problems, Problem{
+				Path:     rel,
+				Line:     m.Line + 1,
+				Token:    m.Token,
+				Msg:      fmt.Sprintf("duplicate of the marker at line %d", prev.Line+1),
+				Severity: Warning,
+				Fixable:  true,
+			})
+			lines[m.Line] = "" // blank it so the balance check sees the file as fixed
+			continue
+		}
+		prev = m
+	}
+
+	if _, err := Parse(strings.Join(lines, "\n"), styles); err != nil {
+		if e, ok := err.(*Error); ok {
+			problems = append(problems, Problem{Path: rel, Line: e.Line, Token: "GF_" + e.Name, Msg: e.Msg, Severity: Failure})
+		} else {
+			problems = append(problems, Problem{Path: rel, Token: "-", Msg: err.Error(), Severity: Failure})
+		}
+	}
+	return problems
+}
+
+// standalone returns the _START/_END tokens the rules for rel use on their
+// own, without the other half (e.g. GF_ACCESS_FLAGS_END).
+func standalone(rel string, rules []Rule) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Path != rel {
+			continue
+		}
+		listed := make(map[string]bool)
+		for _, t := range rule.Tokens {
+			listed[t] = true
+		}
+		for _, t := range rule.Tokens {
+			if other := counterpart(t); other != "" && !listed[other] {
+				tokens[t] = true
+			}
+		}
+	}
+	return tokens
+}
+
+// Fix repairs the trivially fixable problems: a pair marker whose other half
+// is missing gets it inserted right next to the existing half (an empty
+// region), and a marker line repeated on adjacent lines is removed. It returns
+// the number of repairs made.
+func Fix(root string, rules []Rule) (int, error) {
+	problems, err := Check(root, rules)
+	if err != nil {
+		return 0, err
+	}
+	byPath := make(map[string][]Problem)
+	for _, p := range problems {
+		if p.Fixable {
+			byPath[p.Path] = append(byPath[p.Path], p)
+		}
+	}
+
+	fixed := 0
+	for rel, ps := range byPath {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Stat(path)
+		if err != nil {
+			return fixed, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fixed, err
+		}
+		s := string(content)
+		// Remove duplicate lines bottom-up so earlier line numbers stay valid,
+		// then insert missing halves (Line 0).
+		slices.SortFunc(ps, func(a, b Problem) int { return b.Line - a.Line })
+		for _, p := range ps {
+			var ok bool
+			if p.Line > 0 {
+				s, ok = removeLine(s, p.Line-1, p.Token)
+			} else {
+				s, ok = insertPair(s, StylesFor(rel), p.Token)
+			}
+			if ok {
+				fixed++
+			}
+		}
+		if err := os.WriteFile(path, []byte(s), info.Mode().Perm()); err != nil {
+			return fixed, err
+		}
+	}
+	return fixed, nil
+}
+
+// counterpart returns the other half of a START/END token, or "".
+func counterpart(token string) string {
+	switch {
+	case strings.HasSuffix(token, "_START"):
+		return strings.TrimSuffix(token, "_START") + "_END"
+	case strings.HasSuffix(token, "_END"):
+		return strings.TrimSuffix(token, "_END") + "_START"
+	}
+	return ""
+}
+
+// pairFor returns the single existing other half of a START/END token.
+func pairFor(token string, found map[string][]Marker) *Marker {
+	other := counterpart(token)
+	if other == "" {
+		return nil
+	}
+	if ms := found[other]; len(ms) == 1 {
+		return &ms[0]
+	}
+	return nil
+}
+
+func onlyBlankBetween(content string, a, b Marker) bool {
+	return strings.TrimSpace(content[a.Next:b.Offset]) == ""
+}
+
+// insertPair inserts the missing half of token next to its existing half.
+func insertPair(content string, styles []Style, token string) (string, bool) {
+	found := make(map[string][]Marker)
+	for _, m := range Scan(content, styles) {
+		found[m.Token] = append(found[m.Token], m)
+	}
+	other := pairFor(token, found)
+	if other == nil || len(found[token]) > 0 {
+		return content, false
+	}
+	line := other.Indent + other.Style.Format(token) + "\n"
+	if strings.HasSuffix(token, "_END") {
+		at := other.Next
+		if at == len(content) && !strings.HasSuffix(content, "\n") {
+			line = "\n" + strings.TrimSuffix(line, "\n")
+		}
+		return content[:at] + line + content[at:], true
+	}
+	return content[:other.Offset] + line + content[other.Offset:], true
+}
+
+// removeLine deletes the 0-based line idx if it is still the given marker.
+func removeLine(content string, idx int, token string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if idx >= len(lines) || !strings.Contains(lines[idx], token) {
+		return content, false
+	}
+	return strings.Join(slices.Delete(lines, idx, idx+1), "\n"), true
+}
+
+// walk calls fn for every supported, hand-written file under root that
+// mentions a GF_ marker.
+func walk(root string, fn func(rel, content string) error) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && SkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !Supported(path) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Contains(content, []byte("GF_")) || Generated(content) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), string(content))
+	})
+}
+
+// Generated reports whether content carries a "generated, do not edit" header.
+func Generated(content []byte) bool {
+	head := content[:min(len(content), 512)]
+	return bytes.Contains(head, []byte("Code generated")) || bytes.Contains(head, []byte("@generated"))
+}
@@ -0,0 +1,358 @@
+package markers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Style is a comment syntax a marker can be written in, e.g. `// GF_X_START`
+// or `<!-- GF_X_START -->`.
+type Style struct {
+	Open  string
+	Close string
+}
+
+var (
+	Slash = Style{Open: "//"}
+	Dash  = Style{Open: "--"}
+	Hash  = Style{Open: "#"}
+	HTML  = Style{Open: "<!--", Close: "-->"}
+	JSX   = Style{Open: "{/*", Close: "*/}"}
+)
+
+// Format renders a marker token in this style.
+func (s Style) Format(token string) string {
+	if s.Close == "" {
+		return s.Open + " " + token
+	}
+	return s.Open + " " + token + " " + s.Close
+}
+
+var stylesByExt = map[string][]Style{
+	".go":     {Slash},
+	".proto":  {Slash},
+	".ts":     {Slash},
+	".js":     {Slash},
+	".tsx":    {Slash, JSX},
+	".jsx":    {Slash, JSX},
+	".svelte": {Slash, HTML},
+	".vue":    {Slash, HTML},
+	".html":   {Slash, HTML},
+	".sql":    {Dash},
+	".yml":    {Hash},
+	".yaml":   {Hash},
+	".tf":     {Hash, Slash},
+	".sh":     {Hash},
+	".toml":   {Hash},
+}
+
+// StylesFor returns the comment styles markers can use in the file at path, or
+// nil when the file type is not supported.
+func StylesFor(path string) []Style {
+	base := filepath.Base(path)
+	if base == "Makefile" || base == "Dockerfile" || strings.HasPrefix(base, ".env") {
+		return []Style{Hash}
+	}
+	return stylesByExt[filepath.Ext(path)]
+}
+
+// Supported reports whether markers are recognised in the file at path.
+func Supported(path string) bool {
+	return StylesFor(path) != nil
+}
+
+// Kind is the role of a marker line.
+type Kind int
+
+const (
+	// Single is a standalone insertion point, e.g. GF_CONFIG_STRUCT_INSERT.
+	Single Kind = iota
+	Start
+	End
+)
+
+// Marker is a single GF_ marker line.
+type Marker struct {
+	Name   string // marker name without the GF_ prefix and _START/_END suffix
+	Token  string // full token, e.g. GF_STRIPE_START
+	Kind   Kind
+	Line   int // 0-based line index
+	Offset int // byte offset of the start of the marker line
+	Next   int // byte offset just past the marker line and its newline
+	Indent string
+	Style  Style
+}
+
+// Region is a START/END marker pair. Start and End are the 0-based line
+// indexes of the marker lines themselves.
+type Region struct {
+	Name   string
+	Start  int
+	End    int
+	From   int // byte offset of the start of the START line
+	To     int // byte offset just past the END line and its newline
+	Depth  int // nesting depth among regions of any name, 0 for top level
+	Indent string
+	Style  Style
+}
+
+// Error describes malformed markers, with the 1-based line they were found on.
+type Error struct {
+	Line int
+	Name string
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: GF_%s: %s", e.Line, e.Name, e.Msg)
+}
+
+var tokenRe = regexp.MustCompile(`^GF_([A-Z0-9_]+?)(_START|_END)?$`)
+
+// Scan returns every marker line in content written in one of styles.
+func Scan(content string, styles []Style) []Marker {
+	var found []Marker
+	offset := 0
+	for i, line := range strings.Split(content, "\n") {
+		next := min(offset+len(line)+1, len(content))
+		if m, ok := parseLine(line, styles); ok {
+			m.Line = i
+			m.Offset = offset
+			m.Next = next
+			found = append(found, m)
+		}
+		offset = next
+	}
+	return found
+}
+
+func parseLine(line string, styles []Style) (Marker, bool) {
+	trimmed := strings.TrimSpace(line)
+	for _, style := range styles {
+		if !strings.HasPrefix(trimmed, style.Open) {
+			continue
+		}
+		inner := strings.TrimPrefix(trimmed, style.Open)
+		if style.Close != "" {
+			if !strings.HasSuffix(inner, style.Close) {
+				continue
+			}
+			inner = strings.TrimSuffix(inner, style.Close)
+		}
+		fields := strings.Fields(inner)
+		if len(fields) == 0 {
+			continue
+		}
+		sub := tokenRe.FindStringSubmatch(fields[0])
+		if sub == nil {
+			continue
+		}
+		m := Marker{
+			Name:   sub[1],
+			Token:  fields[0],
+			Indent: line[:len(line)-len(strings.TrimLeft(line, " \t"))],
+			Style:  style,
+		}
+		switch sub[2] {
+		case "_START":
+			m.Kind = Start
+		case "_END":
+			m.Kind = End
+		default:
+			m.Kind = Single
+		}
+		return m, true
+	}
+	return Marker{}, false
+}
+
+// Parse pairs every START/END marker in content into regions. Regions may nest
+// and a name may appear any number of times. An END without a START, a START
+// without an END, or regions that overlap without nesting are reported as *Error.
+func Parse(content string, styles []Style) ([]Region, error) {
+	var regions []Region
+	var stack []Marker
+	for _, m := range Scan(content, styles) {
+		switch m.Kind {
+		case Start:
+			stack = append(stack, m)
+		case End:
+			if len(stack) == 0 {
+				return nil, &Error{Line: m.Line + 1, Name: m.Name, Msg: "END marker without matching START"}
+			}
+			top := stack[len(stack)-1]
+			if top.Name != m.Name {
+				return nil, &Error{Line: m.Line + 1, Name: m.Name, Msg: fmt.Sprintf("END marker closes GF_%s_START from line %d", top.Name, top.Line+1)}
+			}
+			stack = stack[:len(stack)-1]
+			regions = append(regions, Region{
+				Name:   m.Name,
+				Start:  top.Line,
+				End:    m.Line,
+				From:   top.Offset,
+				To:     m.Next,
+				Depth:  len(stack),
+				Indent: top.Indent,
+				Style:  top.Style,
+			})
+		}
+	}
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return nil, &Error{Line: top.Line + 1, Name: top.Name, Msg: "START marker without matching END"}
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].Start < regions[j].Start })
+	return regions, nil
+}
+
+// Regions returns the outermost regions named name. Only markers of that name
+// are considered, so malformed markers elsewhere in the file do not matter.
+func Regions(content string, styles []Style, name string) ([]Region, error) {
+	var regions []Region
+	var stack []Marker
+	for _, m := range Scan(content, styles) {
+		if m.Name != name {
+			continue
+		}
+		switch m.Kind {
+		case Start:
+			stack = append(stack, m)
+		case End:
+			if len(stack) == 0 {
+				return nil, &Error{Line: m.Line + 1, Name: name, Msg: "END marker without matching START"}
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				continue // nested duplicate, covered by the outer region
+			}
+			regions = append(regions, Region{Name: name, Start: top.Line, End: m.Line, From: top.Offset, To: m.Next, Indent: top.Indent, Style: top.Style})
+		}
+	}
+	if len(stack) > 0 {
+		return nil, &Error{Line: stack[0].Line + 1, Name: name, Msg: "START marker without matching END"}
+	}
+	return regions, nil
+}
+
+// Replace substitutes every region named name, marker lines included, with
+// body. The body is dedented and re-indented to the START marker's indentation
+// so the same snippet fits regions at different depths. It returns the number
+// of regions replaced.
+func Replace(content string, styles []Style, name, body string) (string, int, error) {
+	regions, err := Regions(content, styles, name)
+	if err != nil || len(regions) == 0 {
+		return content, 0, err
+	}
+	lines := strings.Split(content, "\n")
+	for i := len(regions) - 1; i >= 0; i-- {
+		r := regions[i]
+		repl := Indent(body, r.Indent)
+		lines = splice(lines, r.Start, r.End+1, repl)
+	}
+	return strings.Join(lines, "\n"), len(regions), nil
+}
+
+// ReplaceRequired is Replace for templates that must contain the region: it
+// returns an error when no GF_<name> region is found.
+func ReplaceRequired(content string, styles []Style, name, body string) (string, error) {
+	out, n, err := Replace(content, styles, name, body)
+	if err != nil {
+		return content, err
+	}
+	if n == 0 {
+		return content, fmt.Errorf("markers GF_%s_START .. GF_%s_END not found", name, name)
+	}
+	return out, nil
+}
+
+// Remove deletes every region named name, marker lines included, and returns
+// the number of regions removed.
+func Remove(content string, styles []Style, name string) (string, int, error) {
+	regions, err := Regions(content, styles, name)
+	if err != nil || len(regions) == 0 {
+		return content, 0, err
+	}
+	lines := strings.Split(content, "\n")
+	for i := len(regions) - 1; i >= 0; i-- {
+		lines = splice(lines, regions[i].Start, regions[i].End+1, nil)
+	}
+	return strings.Join(lines, "\n"), len(regions), nil
+}
+
+// Extract returns the text of every region named name, marker lines included,
+// each terminated by a newline.
+func Extract(content string, styles []Style, name string) ([]string, error) {
+	regions, err := Regions(content, styles, name)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]string, 0, len(regions))
+	for _, r := range regions {
+		block := content[r.From:r.To]
+		if !strings.HasSuffix(block, "\n") {
+			block += "\n"
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// Names returns the distinct region names found in content, in order of first
+// appearance.
+func Names(content string, styles []Style) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range Scan(content, styles) {
+		if m.Kind == Start && !seen[m.Name] {
+			seen[m.Name] = true
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// Indent dedents body by its common leading whitespace and prefixes every
+// non-empty line with indent.
+func Indent(body, indent string) []string {
+	body = strings.TrimRight(body, "\n")
+	if body == "" {
+		return nil
+	}
+	lines := strings.Split(body, "\n")
+	common := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		lead := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			common = lead
+			first = false
+			continue
+		}
+		for !strings.HasPrefix(lead, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			out[i] = ""
+			continue
+		}
+		out[i] = indent + strings.TrimPrefix(l, common)
+	}
+	return out
+}
+
+func splice(lines []string, from, to int, repl []string) []string {
+	out := make([]string, 0, len(lines)-(to-from)+len(repl))
+	out = append(out, lines[:from]...)
+	out = append(out, repl...)
+	return append(out, lines[to:]...)
+}
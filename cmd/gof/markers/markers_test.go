@@ -0,0 +1,226 @@
+package markers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	content := "a\n\t// GF_X_START\n<!-- GF_Y -->\n  // GF_X_END trailing words\n// not a marker GF_Z\n-- GF_SQL_START\n"
+	got := Scan(content, []Style{Slash, HTML})
+	want := []Marker{
+		{Name: "X", Token: "GF_X_START", Kind: Start, Line: 1, Offset: 2, Next: 17, Indent: "\t", Style: Slash},
+		{Name: "Y", Token: "GF_Y", Kind: Single, Line: 2, Offset: 17, Next: 31, Style: HTML},
+		{Name: "X", Token: "GF_X_END", Kind: End, Line: 3, Offset: 31, Next: 60, Indent: "  ", Style: Slash},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []Region
+		errLine int
+	}{
+		{
+			name:    "nested",
+			content: "// GF_A_START\n// GF_B_START\n// GF_B_END\n// GF_A_END\n",
+			want: []Region{
+				{Name: "A", Start: 0, End: 3, From: 0, To: 52, Depth: 0, Style: Slash},
+				{Name: "B", Start: 1, End: 2, From: 14, To: 40, Depth: 1, Style: Slash},
+			},
+		},
+		{
+			name:    "END without START",
+			content: "x\n// GF_A_END\n",
+			errLine: 2,
+		},
+		{
+			name:    "START without END",
+			content: "// GF_A_START\nx\n",
+			errLine: 1,
+		},
+		{
+			name:    "overlapping",
+			content: "// GF_A_START\n// GF_B_START\n// GF_A_END\n// GF_B_END\n",
+			errLine: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.content, []Style{Slash})
+			if tt.errLine != 0 {
+				var merr *Error
+				if !errors.As(err, &merr) || merr.Line != tt.errLine {
+					t.Fatalf("Parse() error = %v, want a marker error on line %d", err, tt.errLine)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		body    string
+		want    string
+		n       int
+	}{
+		{
+			name:    "reindents the body",
+			content: "func f() {\n\t// GF_X_START\n\told()\n\t// GF_X_END\n}\n",
+			body:    "    a()\n      b()\n",
+			want:    "func f() {\n\ta()\n\t  b()\n}\n",
+			n:       1,
+		},
+		{
+			name:    "every region",
+			content: "// GF_X_START\n1\n// GF_X_END\nmid\n  // GF_X_START\n2\n  // GF_X_END\n",
+			body:    "new",
+			want:    "new\nmid\n  new\n",
+			n:       2,
+		},
+		{
+			name:    "empty body removes the region",
+			content: "a\n// GF_X_START\nold\n// GF_X_END\nb",
+			body:    "",
+			want:    "a\nb",
+			n:       1,
+		},
+		{
+			name:    "other names untouched",
+			content: "// GF_Y_START\ny\n// GF_Y_END\n",
+			body:    "new",
+			want:    "// GF_Y_START\ny\n// GF_Y_END\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, n, err := Replace(tt.content, []Style{Slash}, "X", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || n != tt.n {
+				t.Errorf("Replace() = %q, %d; want %q, %d", got, n, tt.want, tt.n)
+			}
+		})
+	}
+}
+
+// Extracting a region and putting it back into a file the region was removed
+// from gives the original file.
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		styles  []Style
+		style   Style // the style of the markers in content
+	}{
+		{
+			name:    "go",
+			content: "package main\n\nfunc main() {\n\t// GF_STRIPE_START\n\tstripe()\n\t// GF_STRIPE_END\n}\n",
+			styles:  StylesFor("main.go"),
+			style:   Slash,
+		},
+		{
+			name:    "svelte html",
+			content: "<div>\n    <!-- GF_STRIPE_START -->\n    <a href=\"/payments\">Payments</a>\n    <!-- GF_STRIPE_END -->\n</div>\n",
+			styles:  StylesFor("+layout.svelte"),
+			style:   HTML,
+		},
+		{
+			name:    "jsx",
+			content: "<nav>\n  {/* GF_STRIPE_START */}\n  <Link to=\"/payments\" />\n  {/* GF_STRIPE_END */}\n</nav>\n",
+			styles:  StylesFor("nav.tsx"),
+			style:   JSX,
+		},
+		{
+			name:    "sql",
+			content: "select 1;\n-- GF_STRIPE_START\ncreate table payments ();\n-- GF_STRIPE_END\n",
+			styles:  StylesFor("query.sql"),
+			style:   Dash,
+		},
+		{
+			name:    "makefile, nested region",
+			content: "all:\n# GF_STRIPE_START\n# GF_STRIPE_START\n\tstripe listen\n# GF_STRIPE_END\n# GF_STRIPE_END\n",
+			styles:  StylesFor("Makefile"),
+			style:   Hash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := Extract(tt.content, tt.styles, "STRIPE")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(blocks) != 1 {
+				t.Fatalf("Extract() = %q, want one block", blocks)
+			}
+			// Leave a placeholder region where the block was, as integration
+			// stripping does, and fill it back in.
+			placeholder := tt.style.Format("GF_STRIPE_START") + "\n" + tt.style.Format("GF_STRIPE_END")
+			stripped, n, err := Replace(tt.content, tt.styles, "STRIPE", placeholder)
+			if err != nil || n != 1 {
+				t.Fatalf("Replace() = %d, %v", n, err)
+			}
+			if names := Names(stripped, tt.styles); !reflect.DeepEqual(names, []string{"STRIPE"}) {
+				t.Fatalf("Names() = %v after stripping", names)
+			}
+			restored, err := ReplaceRequired(stripped, tt.styles, "STRIPE", blocks[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if restored != tt.content {
+				t.Errorf("round trip = %q, want %q", restored, tt.content)
+			}
+			removed, n, err := Remove(restored, tt.styles, "STRIPE")
+			if err != nil || n != 1 {
+				t.Fatalf("Remove() = %d, %v", n, err)
+			}
+			if got := Names(removed, tt.styles); got != nil {
+				t.Errorf("Names() = %v after Remove, want none", got)
+			}
+		})
+	}
+}
+
+func TestReplaceRequired(t *testing.T) {
+	if _, err := ReplaceRequired("no markers\n", []Style{Slash}, "X", "body"); err == nil {
+		t.Error("ReplaceRequired() without the region succeeded")
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	var core, billing int
+	for _, rule := range RulesFor("service-billing") {
+		switch {
+		case strings.HasPrefix(rule.Path, "app/service-core/"):
+			core++
+		case strings.HasPrefix(rule.Path, "app/service-billing/"):
+			billing++
+			if !rule.Service {
+				t.Errorf("RulesFor() moved %s, which is not a per-service rule", rule.Path)
+			}
+		}
+	}
+	// config.go is only edited by `gof add`, which targets service-core.
+	if core != 1 || billing != 4 {
+		t.Errorf("RulesFor() has %d service-core and %d service-billing rules, want 1 and 4", core, billing)
+	}
+	if got := len(RulesFor("service-core", "service-billing")); got != len(Rules)+4 {
+		t.Errorf("RulesFor() for two services has %d rules, want %d", got, len(Rules)+4)
+	}
+}
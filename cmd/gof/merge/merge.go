@@ -0,0 +1,226 @@
+// Package merge implements a line-based three-way merge (diff3) for carrying
+// template updates into projects that have been edited since they were
+// generated.
+package merge
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Labels name the three sides in conflict markers.
+type Labels struct {
+	Ours   string // the project
+	Base   string // the template the project was generated from
+	Theirs string // the template being upgraded to
+}
+
+// Text merges the changes from base to theirs into ours. Hunks changed on
+// only one side are taken from that side; hunks changed differently on both
+// sides are written with diff3-style conflict markers. It returns the merged
+// text and the number of conflicts.
+func Text(base, ours, theirs string, labels Labels) (string, int) {
+	// A missing newline at the end of a file would make its last line differ
+	// from the same line elsewhere; merge with newlines everywhere and treat
+	// the final newline as a change of its own.
+	final := finalNewline(ours)
+	if final == finalNewline(base) {
+		final = finalNewline(theirs)
+	}
+	merged, conflicts := Lines(splitLines(terminate(base)), splitLines(terminate(ours)), splitLines(terminate(theirs)), labels)
+	s := strings.Join(merged, "")
+	if !final {
+		s = strings.TrimSuffix(s, "\n")
+	}
+	return s, conflicts
+}
+
+func finalNewline(s string) bool {
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+func terminate(s string) string {
+	if finalNewline(s) {
+		return s
+	}
+	return s + "\n"
+}
+
+// Lines is Text on lines that keep their trailing newline.
+func Lines(base, ours, theirs []string, labels Labels) ([]string, int) {
+	ma := matches(base, ours)
+	mb := matches(base, theirs)
+
+	var out []string
+	conflicts := 0
+	o, a, b := 0, 0, 0
+	for {
+		// The next stable line is a base line both sides kept.
+		j := o
+		for j < len(base) && (ma[j] < 0 || mb[j] < 0) {
+			j++
+		}
+		if j < len(base) && j == o && ma[j] == a && mb[j] == b {
+			out = append(out, base[o])
+			o, a, b = o+1, a+1, b+1
+			continue
+		}
+
+		endA, endB := len(ours), len(theirs)
+		if j < len(base) {
+			endA, endB = ma[j], mb[j]
+		}
+		chunkO, chunkA, chunkB := base[o:j], ours[a:endA], theirs[b:endB]
+		switch {
+		case equal(chunkA, chunkO):
+			out = append(out, chunkB...)
+		case equal(chunkB, chunkO), equal(chunkA, chunkB):
+			out = append(out, chunkA...)
+		default:
+			conflicts++
+			out = append(out, "<<<<<<< "+labels.Ours+"\n")
+			out = appendTerminated(out, chunkA)
+			out = append(out, "||||||| "+labels.Base+"\n")
+			out = appendTerminated(out, chunkO)
+			out = append(out, "=======\n")
+			out = appendTerminated(out, chunkB)
+			out = append(out, ">>>>>>> "+labels.Theirs+"\n")
+		}
+		if j == len(base) {
+			return out, conflicts
+		}
+		o, a, b = j, endA, endB
+	}
+}
+
+// IsBinary reports whether content looks like a binary file, which is never
+// merged line by line.
+func IsBinary(content []byte) bool {
+	n := len(content)
+	if n > 8000 {
+		n = 8000
+	}
+	return bytes.IndexByte(content[:n], 0) >= 0
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// appendTerminated appends lines, adding a newline to a last line without
+// one so the conflict marker that follows starts on its own line.
+func appendTerminated(out []string, lines []string) []string {
+	for _, l := range lines {
+		if !strings.HasSuffix(l, "\n") {
+			l += "\n"
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns, for every line of a, the index of the line of b it is
+// paired with in a shortest edit script from a to b, or -1 when the line is
+// deleted. It uses Myers' O(ND) algorithm after trimming the common prefix
+// and suffix.
+func matches(a, b []string) []int {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		m[pre] = pre
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		m[len(a)-1-suf] = len(b) - 1 - suf
+		suf++
+	}
+	x, y := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	for _, p := range myers(x, y) {
+		m[pre+p[0]] = pre + p[1]
+	}
+	return m
+}
+
+// myers returns the pairs of equal lines (index in a, index in b) kept by a
+// shortest edit script. Round d can only reach diagonals -d..d, so the trace
+// keeps those (plus one on each side) instead of all of v: O(D²) memory
+// rather than O((N+M)·D).
+func myers(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return nil
+	}
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks the trace of myers back from (x, y). trace[d] holds
+// diagonals -d-1..d+1, so diagonal k is at index d+1+k.
+func backtrack(trace [][]int, x, y int) [][2]int {
+	var pairs [][2]int
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k] < v[d+k+2]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+1+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			pairs = append(pairs, [2]int{x, y})
+		}
+		if d > 0 {
+			x, y = prevX, prevY
+		}
+	}
+	for i, j := 0, len(pairs)-1; i < j; i, j = i+1, j-1 {
+		pairs[i], pairs[j] = pairs[j], pairs[i]
+	}
+	return pairs
+}
@@ -0,0 +1,159 @@
+package merge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	labels := Labels{Ours: "project", Base: "v1", Theirs: "v2"}
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflicts          int
+	}{
+		{
+			name: "unchanged",
+			base: "a\nb\nc\n", ours: "a\nb\nc\n", theirs: "a\nb\nc\n",
+			want: "a\nb\nc\n",
+		},
+		{
+			name: "only theirs changed",
+			base: "a\nb\nc\n", ours: "a\nb\nc\n", theirs: "a\nB\nc\n",
+			want: "a\nB\nc\n",
+		},
+		{
+			name: "only ours changed",
+			base: "a\nb\nc\n", ours: "a\nB\nc\n", theirs: "a\nb\nc\n",
+			want: "a\nB\nc\n",
+		},
+		{
+			name: "both sides change different lines",
+			base: "a\nb\nc\nd\ne\n", ours: "A\nb\nc\nd\ne\n", theirs: "a\nb\nc\nd\nE\n",
+			want: "A\nb\nc\nd\nE\n",
+		},
+		{
+			name: "both sides make the same change",
+			base: "a\nb\nc\n", ours: "a\nX\nc\n", theirs: "a\nX\nc\n",
+			want: "a\nX\nc\n",
+		},
+		{
+			name: "insertions on both sides",
+			base: "a\nb\nc\n", ours: "x\na\nb\nc\n", theirs: "a\nb\nc\ny\n",
+			want: "x\na\nb\nc\ny\n",
+		},
+		{
+			name: "theirs deletes a line",
+			base: "a\nb\nc\n", ours: "a\nb\nc\nd\n", theirs: "a\nc\n",
+			want: "a\nc\nd\n",
+		},
+		{
+			name: "conflict",
+			base: "a\nb\nc\n", ours: "a\nours\nc\n", theirs: "a\ntheirs\nc\n",
+			want:      "a\n<<<<<<< project\nours\n||||||| v1\nb\n=======\ntheirs\n>>>>>>> v2\nc\n",
+			conflicts: 1,
+		},
+		{
+			name: "two conflicts",
+			base: "a\nb\nc\nd\ne\n", ours: "a\n1\nc\n3\ne\n", theirs: "a\n2\nc\n4\ne\n",
+			want: "a\n<<<<<<< project\n1\n||||||| v1\nb\n=======\n2\n>>>>>>> v2\nc\n" +
+				"<<<<<<< project\n3\n||||||| v1\nd\n=======\n4\n>>>>>>> v2\ne\n",
+			conflicts: 2,
+		},
+		{
+			name: "no final newline anywhere",
+			base: "a\nb", ours: "a\nb", theirs: "a\nB",
+			want: "a\nB",
+		},
+		{
+			name: "theirs drops the final newline",
+			base: "a\nb\n", ours: "a\nb\n", theirs: "a\nb",
+			want: "a\nb",
+		},
+		{
+			name: "ours adds the final newline, theirs changes the last line",
+			base: "a\nb", ours: "a\nb\n", theirs: "a\nB",
+			want: "a\nB\n",
+		},
+		{
+			name: "conflict on a last line without newline",
+			base: "a\nb", ours: "a\nours", theirs: "a\ntheirs",
+			want:      "a\n<<<<<<< project\nours\n||||||| v1\nb\n=======\ntheirs\n>>>>>>> v2",
+			conflicts: 1,
+		},
+		{
+			name: "empty base, same content added",
+			base: "", ours: "a\n", theirs: "a\n",
+			want: "a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := Text(tt.base, tt.ours, tt.theirs, labels)
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("Text() = %q, %d conflicts; want %q, %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []int
+	}{
+		{name: "equal", a: "abc", b: "abc", want: []int{0, 1, 2}},
+		{name: "all deleted", a: "abc", b: "", want: []int{-1, -1, -1}},
+		{name: "all replaced", a: "abc", b: "xyz", want: []int{-1, -1, -1}},
+		{name: "insertion", a: "ac", b: "abc", want: []int{0, 2}},
+		{name: "deletion", a: "abc", b: "ac", want: []int{0, -1, 1}},
+		{name: "interleaved", a: "abcabba", b: "cbabac", want: []int{-1, -1, 0, 1, -1, 3, 4}},
+		{name: "far apart", a: "xabcdefghy", b: "1abc23defgh4", want: []int{-1, 1, 2, 3, 6, 7, 8, 9, 10, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matches(strings.Split(tt.a, ""), strings.Split(tt.b, ""))
+			if len(got) != len(tt.want) {
+				t.Fatalf("matches() = %v, want %v", got, tt.want)
+			}
+			// Any shortest edit script will do: check it keeps as many lines
+			// as want and pairs equal lines in order.
+			kept, wantKept, last := 0, 0, -1
+			for i, j := range got {
+				if tt.want[i] >= 0 {
+					wantKept++
+				}
+				if j < 0 {
+					continue
+				}
+				kept++
+				if j <= last || tt.a[i] != tt.b[j] {
+					t.Fatalf("matches() = %v pairs %d with %d", got, i, j)
+				}
+				last = j
+			}
+			if kept != wantKept {
+				t.Errorf("matches() = %v keeps %d lines, want %d (%v)", got, kept, wantKept, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{"", false},
+		{"package main\n", false},
+		{"PNG\x00\x01", true},
+		{strings.Repeat("a", 9000) + "\x00", false},
+	}
+	for _, tt := range tests {
+		if got := IsBinary([]byte(tt.content)); got != tt.want {
+			t.Errorf("IsBinary(%.20q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package naming converts the snake_case model and column names of
+// gofast.json to the spellings generated code uses for them.
+package naming
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Pascal converts snake_case to PascalCase (e.g., "user_profile" ->
+// "UserProfile"): proto message, service and Go field names.
+func Pascal(s string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(s, "_") {
+		if p == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+	}
+	return b.String()
+}
+
+// Camel converts snake_case to camelCase (e.g., "published_at" ->
+// "publishedAt"): the field names of protobuf-generated TypeScript.
+func Camel(s string) string {
+	parts := strings.Split(s, "_")
+	if len(parts) == 1 {
+		return s
+	}
+	return parts[0] + Pascal(strings.Join(parts[1:], "_"))
+}
+
+// Title turns snake_case into a label (e.g., "published_at" ->
+// "Published At").
+func Title(s string) string {
+	parts := strings.Split(s, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ReplaceFieldAccess replaces the field accesses ".field" in content with
+// ".replacement", leaving longer names that start with field alone.
+func ReplaceFieldAccess(content, field, replacement string) string {
+	pattern := regexp.MustCompile(`\.` + regexp.QuoteMeta(field) + `\b`)
+	return pattern.ReplaceAllString(content, "."+replacement)
+}
@@ -0,0 +1,42 @@
+package naming
+
+import "testing"
+
+func TestCases(t *testing.T) {
+	tests := []struct {
+		in                   string
+		pascal, camel, title string
+	}{
+		{"note", "Note", "note", "Note"},
+		{"user_profile", "UserProfile", "userProfile", "User Profile"},
+		{"published_at_2", "PublishedAt2", "publishedAt2", "Published At 2"},
+		{"trailing_", "Trailing", "trailing", "Trailing "},
+	}
+	for _, tt := range tests {
+		if got := Pascal(tt.in); got != tt.pascal {
+			t.Errorf("Pascal(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+		if got := Camel(tt.in); got != tt.camel {
+			t.Errorf("Camel(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+		if got := Title(tt.in); got != tt.title {
+			t.Errorf("Title(%q) = %q, want %q", tt.in, got, tt.title)
+		}
+	}
+}
+
+func TestReplaceFieldAccess(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"data.skeleton.name", "data.userProfile.name"},
+		{"data.skeletons", "data.skeletons"},
+		{"skeleton.name", "skeleton.name"},
+		{"a.skeleton, b.skeleton)", "a.userProfile, b.userProfile)"},
+	}
+	for _, tt := range tests {
+		if got := ReplaceFieldAccess(tt.content, "skeleton", "userProfile"); got != tt.want {
+			t.Errorf("ReplaceFieldAccess(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+// Package next generates the pages of the Next.js client (App Router,
+// Connect-ES) for a model, from the skeleton pages in app/service-next.
+package next
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gertd/go-pluralize"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/naming"
+)
+
+type Column struct {
+	Name string
+	Type string
+}
+
+var pluralizeClient = pluralize.NewClient()
+
+func GetModelPath(modelName string) string {
+	return "/models/" + pluralizeClient.Plural(modelName)
+}
+
+// FormatProject installs the client's dependencies and formats it. root is
+// the project directory. The App Router finds routes on disk, so unlike
+// TanStack there is no route tree to regenerate.
+func FormatProject(root string) error {
+	cmd := "npm ci && npm run format"
+	execCmd := exec.Command("bash", "-c", cmd)
+	execCmd.Dir = filepath.Join(root, "app", "service-next")
+	out, err := execCmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("running npm commands: %w\nOutput: %s", err, string(out))
+	}
+	return nil
+}
+
+func GenerateNextScaffolding(modelName string, columns []Column) error {
+	if err := generateClientConnect(modelName); err != nil {
+		return fmt.Errorf("generating client connect.ts: %w", err)
+	}
+	if err := generateClientListPage(modelName, columns); err != nil {
+		return fmt.Errorf("generating client list page: %w", err)
+	}
+	if err := generateClientDetailPage(modelName, columns); err != nil {
+		return fmt.Errorf("generating client detail page: %w", err)
+	}
+	return nil
+}
+
+func generateClientConnect(modelName string) error {
+	path := "./app/service-next/src/lib/connect.ts"
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading connect.ts: %w", err)
+	}
+	s := string(b)
+
+	pascalName := naming.Pascal(modelName)
+	serviceToken := pascalName + "Service"
+	clientExport := "export const " + modelName + "_client = createClient(" + serviceToken + ", transport)"
+
+	if !strings.Contains(s, serviceToken) {
+		marker := "from './gen/proto/v1/main_pb'"
+		idx := strings.Index(s, marker)
+		if idx == -1 {
+			return fmt.Errorf("main_pb import not found in connect.ts")
+		}
+		pre := s[:idx]
+		braceOpen := strings.LastIndex(pre, "{")
+		braceClose := strings.LastIndex(pre, "}")
+		if braceOpen == -1 || braceClose == -1 || braceClose < braceOpen {
+			return fmt.Errorf("malformed main_pb import in connect.ts")
+		}
+		importList := strings.TrimSpace(pre[braceOpen+1 : braceClose])
+		if importList == "" {
+			importList = serviceToken
+		} else {
+			if !strings.HasSuffix(importList, ",") {
+				importList += ","
+			}
+			importList += "\n  " + serviceToken
+		}
+		s = s[:braceOpen+1] + "\n  " + importList + "\n" + s[braceClose:]
+	}
+
+	if !strings.Contains(s, clientExport) {
+		insertAfter := "export const skeleton_client = createClient(SkeletonService, transport)"
+		pos := strings.Index(s, insertAfter)
+		if pos == -1 {
+			if !strings.HasSuffix(s, "\n") {
+				s += "\n"
+			}
+			s += clientExport + "\n"
+		} else {
+			lineEnd := pos + len(insertAfter)
+			s = s[:lineEnd] + "\n" + clientExport + s[lineEnd:]
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+		return fmt.Errorf("writing connect.ts: %w", err)
+	}
+	return nil
+}
+
+func generateClientListPage(modelName string, columns []Column) error {
+	sourcePath := "./app/service-next/src/app/(app)/models/skeletons/page.tsx"
+	pluralLower := pluralizeClient.Plural(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
+
+	destDir := filepath.Join("app/service-next/src/app/(app)/models", pluralLower)
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return fmt.Errorf("creating destination directory %s: %w", destDir, err)
+	}
+	destPath := filepath.Join(destDir, "page.tsx")
+
+	contentBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading template file %s: %w", sourcePath, err)
+	}
+
+	s := string(contentBytes)
+	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
+	s = strings.ReplaceAll(s, "skeletons", pluralLower)
+	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
+	camelName := naming.Camel(modelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
+	s = strings.ReplaceAll(s, "skeleton", modelName)
+
+	var headersBuilder strings.Builder
+	for _, c := range columns {
+		headersBuilder.WriteString("                <th role=\"columnheader\">")
+		headersBuilder.WriteString(naming.Title(c.Name))
+		headersBuilder.WriteString("</th>\n")
+	}
+	headersBuilder.WriteString("                <th role=\"columnheader\">Created</th>\n")
+	headersBuilder.WriteString("                <th role=\"columnheader\">Updated</th>\n")
+
+	var cellsBuilder strings.Builder
+	for _, c := range columns {
+		field := naming.Camel(c.Name)
+		switch c.Type {
+		case "date":
+			cellsBuilder.WriteString("                    <td>{new Date(" + modelName + "." + field + ").toLocaleDateString()}</td>\n")
+		case "bool":
+			cellsBuilder.WriteString("                    <td>{" + modelName + "." + field + " ? 'Yes' : 'No'}</td>\n")
+		default:
+			cellsBuilder.WriteString("                    <td>{" + modelName + "." + field + "}</td>\n")
+		}
+	}
+	cellsBuilder.WriteString("                    <td>{new Date(" + modelName + ".created).toLocaleDateString()}</td>\n")
+	cellsBuilder.WriteString("                    <td>{new Date(" + modelName + ".updated).toLocaleDateString()}</td>\n")
+
+	var replaceErr error
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_HEADERS", headersBuilder.String())
+	if replaceErr != nil {
+		return fmt.Errorf("replacing headers: %w", replaceErr)
+	}
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_CELLS", cellsBuilder.String())
+	if replaceErr != nil {
+		return fmt.Errorf("replacing cells: %w", replaceErr)
+	}
+
+	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
+		return fmt.Errorf("writing client list page %s: %w", destPath, err)
+	}
+	return nil
+}
+
+func generateClientDetailPage(modelName string, columns []Column) error {
+	sourcePath := "./app/service-next/src/app/(app)/models/skeletons/[skeleton_id]/page.tsx"
+	pluralLower := pluralizeClient.Plural(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
+
+	destDir := filepath.Join("app/service-next/src/app/(app)/models", pluralLower, "["+modelName+"_id]")
+	if err := os.MkdirAll(destDir, 0o755); err != nil {
+		return fmt.Errorf("creating destination directory %s: %w", destDir, err)
+	}
+	destPath := filepath.Join(destDir, "page.tsx")
+
+	contentBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return fmt.Errorf("reading template file %s: %w", sourcePath, err)
+	}
+
+	s := string(contentBytes)
+	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
+	s = strings.ReplaceAll(s, "skeletons", pluralLower)
+	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
+	camelName := naming.Camel(modelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
+	s = strings.ReplaceAll(s, "skeleton: {", camelName+": {")
+	s = strings.ReplaceAll(s, "skeleton", modelName)
+
+	var emptyBuilder strings.Builder
+	emptyIndent := "  "
+	emptyBuilder.WriteString(emptyIndent + "created: '',\n")
+	emptyBuilder.WriteString(emptyIndent + "updated: '',\n")
+	emptyBuilder.WriteString(emptyIndent + "id: '',\n")
+	for _, c := range columns {
+		field := naming.Camel(c.Name)
+		if c.Type == "bool" {
+			emptyBuilder.WriteString(emptyIndent + field + ": false,\n")
+			continue
+		}
+		emptyBuilder.WriteString(emptyIndent + field + ": '',\n")
+	}
+	emptySnippet := strings.TrimRight(emptyBuilder.String(), "\n")
+
+	var formDataBuilder strings.Builder
+	formDataIndent := "    "
+	for _, c := range columns {
+		field := naming.Camel(c.Name)
+		if c.Type == "bool" {
+			formDataBuilder.WriteString(formDataIndent + "const " + field + " = formData.get('" + c.Name + "') === 'on'\n")
+			continue
+		}
+		formDataBuilder.WriteString(formDataIndent + "const " + field + " = formData.get('" + c.Name + "')?.toString() ?? ''\n")
+	}
+	formDataSnippet := strings.TrimRight(formDataBuilder.String(), "\n")
+
+	var payloadBuilder strings.Builder
+	for _, c := range columns {
+		field := naming.Camel(c.Name)
+		payloadBuilder.WriteString("            " + field + ",\n")
+	}
+	payloadSnippet := strings.TrimRight(payloadBuilder.String(), "\n")
+
+	var fieldsBuilder strings.Builder
+	for _, c := range columns {
+		label := naming.Title(c.Name)
+		field := naming.Camel(c.Name)
+		switch c.Type {
+		case "string":
+			fieldsBuilder.WriteString("          <label className=\"label\" htmlFor=\"" + c.Name + "\">\n")
+			fieldsBuilder.WriteString("            " + label + "\n")
+			fieldsBuilder.WriteString("          </label>\n")
+			fieldsBuilder.WriteString("          <div>\n")
+			fieldsBuilder.WriteString("            <input\n")
+			fieldsBuilder.WriteString("              id=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              type=\"text\"\n")
+			fieldsBuilder.WriteString("              name=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              required\n")
+			fieldsBuilder.WriteString("              className=\"input input-bordered validator w-full\"\n")
+			fieldsBuilder.WriteString("              defaultValue={" + modelName + "." + field + "}\n")
+			fieldsBuilder.WriteString("            />\n")
+			fieldsBuilder.WriteString("            <div className=\"validator-hint\">Enter at least 3 characters</div>\n")
+			fieldsBuilder.WriteString("          </div>\n")
+		case "number":
+			fieldsBuilder.WriteString("          <label className=\"label\" htmlFor=\"" + c.Name + "\">\n")
+			fieldsBuilder.WriteString("            " + label + "\n")
+			fieldsBuilder.WriteString("          </label>\n")
+			fieldsBuilder.WriteString("          <div>\n")
+			fieldsBuilder.WriteString("            <input\n")
+			fieldsBuilder.WriteString("              id=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              type=\"number\"\n")
+			fieldsBuilder.WriteString("              name=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              required\n")
+			fieldsBuilder.WriteString("              className=\"input input-bordered validator w-full\"\n")
+			fieldsBuilder.WriteString("              defaultValue={" + modelName + "." + field + "}\n")
+			fieldsBuilder.WriteString("            />\n")
+			fieldsBuilder.WriteString("            <div className=\"validator-hint\">Enter a positive number</div>\n")
+			fieldsBuilder.WriteString("          </div>\n")
+		case "date":
+			fieldsBuilder.WriteString("          <label className=\"label\" htmlFor=\"" + c.Name + "\">\n")
+			fieldsBuilder.WriteString("            " + label + "\n")
+			fieldsBuilder.WriteString("          </label>\n")
+			fieldsBuilder.WriteString("          <div>\n")
+			fieldsBuilder.WriteString("            <input\n")
+			fieldsBuilder.WriteString("              id=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              type=\"date\"\n")
+			fieldsBuilder.WriteString("              name=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              required\n")
+			fieldsBuilder.WriteString("              className=\"input input-bordered validator w-full\"\n")
+			fieldsBuilder.WriteString("              defaultValue={formatDate(" + modelName + "." + field + ")}\n")
+			fieldsBuilder.WriteString("            />\n")
+			fieldsBuilder.WriteString("            <div className=\"validator-hint\">Select a valid date</div>\n")
+			fieldsBuilder.WriteString("          </div>\n")
+		case "bool":
+			fieldsBuilder.WriteString("          <label className=\"label my-2 cursor-pointer\" htmlFor=\"" + c.Name + "\">\n")
+			fieldsBuilder.WriteString("            <span className=\"label-text\">" + label + "</span>\n")
+			fieldsBuilder.WriteString("            <input\n")
+			fieldsBuilder.WriteString("              id=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              name=\"" + c.Name + "\"\n")
+			fieldsBuilder.WriteString("              type=\"checkbox\"\n")
+			fieldsBuilder.WriteString("              className=\"toggle\"\n")
+			fieldsBuilder.WriteString("              defaultChecked={" + modelName + "." + field + "}\n")
+			fieldsBuilder.WriteString("            />\n")
+			fieldsBuilder.WriteString("          </label>\n")
+		}
+	}
+	fieldsSnippet := strings.TrimRight(fieldsBuilder.String(), "\n")
+
+	var replaceErr error
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EMPTY", emptySnippet)
+	if replaceErr != nil {
+		return fmt.Errorf("replacing empty defaults: %w", replaceErr)
+	}
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FORMDATA", formDataSnippet)
+	if replaceErr != nil {
+		return fmt.Errorf("replacing form data: %w", replaceErr)
+	}
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_CREATE_FIELDS", payloadSnippet)
+	if replaceErr != nil {
+		return fmt.Errorf("replacing create fields: %w", replaceErr)
+	}
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EDIT_FIELDS", payloadSnippet)
+	if replaceErr != nil {
+		return fmt.Errorf("replacing edit fields: %w", replaceErr)
+	}
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FIELDS", fieldsSnippet)
+	if replaceErr != nil {
+		return fmt.Errorf("replacing UI fields: %w", replaceErr)
+	}
+
+	hasDateColumn := false
+	for _, c := range columns {
+		if c.Type == "date" {
+			hasDateColumn = true
+			break
+		}
+	}
+
+	var outLines []string
+	inFormatDateFunc := false
+	braceDepth := 0
+	for line := range strings.SplitSeq(s, "\n") {
+		skip := false
+		if !hasDateColumn {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "function formatDate(") {
+				inFormatDateFunc = true
+				braceDepth = 0
+				skip = true
+			}
+			if inFormatDateFunc {
+				skip = true
+				for _, ch := range line {
+					if ch == '{' {
+						braceDepth++
+					} else if ch == '}' {
+						braceDepth--
+						if braceDepth == 0 {
+							inFormatDateFunc = false
+							break
+						}
+					}
+				}
+			}
+		}
+		if !skip {
+			outLines = append(outLines, line)
+		}
+	}
+	s = strings.Join(outLines, "\n")
+
+	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
+		return fmt.Errorf("writing client detail page %s: %w", destPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package ports finds the host ports a project's compose files publish and
+// moves a service from one port to another across the files that mention it.
+package ports
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/merge"
+)
+
+// Check reports whether port is a usable TCP port number.
+func Check(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 || strconv.Itoa(n) != port {
+		return fmt.Errorf("invalid port %q: must be a number between 1 and 65535", port)
+	}
+	return nil
+}
+
+// Binding is a host port published by a compose file.
+type Binding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Port string `json:"port"`
+}
+
+var (
+	// shortSyntax matches a "- [ip:]host:container[/proto]" ports entry.
+	shortSyntax = regexp.MustCompile(`^\s*-\s*["']?(?:\d{1,3}(?:\.\d{1,3}){3}:)?(\d+):\d+(?:/(?:tcp|udp))?["']?\s*(?:#.*)?$`)
+	// longSyntax matches the "published:" key of a long-syntax ports entry.
+	longSyntax = regexp.MustCompile(`^\s*(?:-\s*)?published:\s*["']?(\d+)["']?\s*(?:#.*)?$`)
+)
+
+// Bindings lists the host ports published by the docker-compose*.yml files
+// in root, in file and line order.
+func Bindings(root string) ([]Binding, error) {
+	files, err := filepath.Glob(filepath.Join(root, "docker-compose*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+	var bindings []Binding
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		sc := bufio.NewScanner(bytes.NewReader(data))
+		for n := 1; sc.Scan(); n++ {
+			m := shortSyntax.FindStringSubmatch(sc.Text())
+			if m == nil {
+				m = longSyntax.FindStringSubmatch(sc.Text())
+			}
+			if m != nil {
+				bindings = append(bindings, Binding{File: filepath.Base(file), Line: n, Port: m[1]})
+			}
+		}
+	}
+	return bindings, nil
+}
+
+// Free returns preferred if no port in used is equal to it, otherwise the
+// next higher port that is free.
+func Free(preferred string, used []string) string {
+	n, err := strconv.Atoi(preferred)
+	if err != nil {
+		return preferred
+	}
+	for slices.Contains(used, strconv.Itoa(n)) {
+		n++
+	}
+	return strconv.Itoa(n)
+}
+
+// Files are the files that belong to one service: its compose file and its
+// directory, both relative to the project root.
+type Files struct {
+	Compose string
+	Dir     string
+}
+
+// Change is a file Rewrite modified and how many port references it moved.
+type Change struct {
+	Path  string `json:"path"`
+	Count int    `json:"count"`
+}
+
+// portContext matches the text right before a port number that makes it the
+// port of a listener: "PORT=", "port: ", "published: ", "target: ",
+// "--port ", the exec form "--port", "...", "EXPOSE " and "-p ".
+var portContext = regexp.MustCompile(`(?i)(?:(?:port|published|target)\s*[=:]\s*["']?|--port(?:[= ]|["']\s*,\s*)["']?|expose\s+|-p\s+)$`)
+
+// urlHost matches the text right before the port of a URL: "//host:".
+var urlHost = regexp.MustCompile(`//[A-Za-z0-9._\-\[\]]+:$`)
+
+// Rewrite moves the service owning files from port from to port to below
+// root. In the service's own compose file and directory every reference
+// to the port is moved: port mappings, "PORT=" settings, --port flags,
+// EXPOSE lines and URLs. Everywhere else only URLs ("//host:port") are
+// moved, since those are how other services and clients reach it; when
+// shared is set another service uses the same port, so such URLs are
+// ambiguous and left alone.
+func Rewrite(root string, files Files, from, to string, shared bool) ([]Change, error) {
+	if from == to {
+		return nil, nil
+	}
+	compose := filepath.Join(root, filepath.FromSlash(files.Compose))
+	dir := filepath.Join(root, filepath.FromSlash(files.Dir))
+	var changes []Change
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if markers.SkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if skipFile(d.Name()) {
+			return nil
+		}
+		own := path == compose || strings.HasPrefix(path, dir+string(filepath.Separator))
+		if !own && shared {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || info.Size() > 1<<20 {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if merge.IsBinary(src) {
+			return nil
+		}
+		out, n := replacePort(src, from, to, own)
+		if n == 0 {
+			return nil
+		}
+		if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		changes = append(changes, Change{Path: filepath.ToSlash(rel), Count: n})
+		return nil
+	})
+	return changes, err
+}
+
+// skipFile reports whether a file never holds service ports: the project
+// config and package manager lock files.
+func skipFile(name string) bool {
+	switch name {
+	case "gofast.json", "gofast.json.lock", "package-lock.json", "pnpm-lock.yaml", "yarn.lock", "bun.lock", "bun.lockb", "go.sum":
+		return true
+	}
+	return false
+}
+
+// replacePort replaces the occurrences of the port number from that stand
+// for a port. In own files that is any number next to a ':' or after a
+// portContext; elsewhere only URL ports.
+func replacePort(src []byte, from, to string, own bool) ([]byte, int) {
+	var b bytes.Buffer
+	count := 0
+	last := 0
+	for i := 0; i+len(from) <= len(src); {
+		j := bytes.Index(src[i:], []byte(from))
+		if j < 0 {
+			break
+		}
+		start := i + j
+		end := start + len(from)
+		i = end
+		if start > 0 && isDigit(src[start-1]) || end < len(src) && isDigit(src[end]) {
+			continue
+		}
+		// Only look back on the same line.
+		lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+		before := src[lineStart:start]
+		isPort := urlHost.Match(before)
+		if own && !isPort {
+			isPort = start > 0 && src[start-1] == ':' ||
+				end < len(src) && src[end] == ':' ||
+				portContext.Match(before)
+		}
+		if !isPort {
+			continue
+		}
+		b.Write(src[last:start])
+		b.WriteString(to)
+		last = end
+		count++
+	}
+	if count == 0 {
+		return src, 0
+	}
+	b.Write(src[last:])
+	return b.Bytes(), count
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
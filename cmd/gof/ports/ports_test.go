@@ -0,0 +1,164 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReplacePort(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		own   bool
+		want  string
+		count int
+	}{
+		{name: "compose mapping", src: `      - "4000:4000"`, own: true, want: `      - "4001:4001"`, count: 2},
+		{name: "published", src: "        published: 4000", own: true, want: "        published: 4001", count: 1},
+		{name: "env setting", src: "PORT=4000\n", own: true, want: "PORT=4001\n", count: 1},
+		{name: "port flag", src: "run --port 4000", own: true, want: "run --port 4001", count: 1},
+		{name: "exec form flag", src: `CMD ["serve", "--port", "4000"]`, own: true, want: `CMD ["serve", "--port", "4001"]`, count: 1},
+		{name: "expose", src: "EXPOSE 4000", own: true, want: "EXPOSE 4001", count: 1},
+		{name: "host:port", src: "addr := \"localhost:4000\"", own: true, want: "addr := \"localhost:4001\"", count: 1},
+		{name: "url elsewhere", src: "CORE_URL=http://core:4000/api", want: "CORE_URL=http://core:4001/api", count: 1},
+		{name: "host:port without url elsewhere", src: "addr: localhost:4000"},
+		{name: "port setting elsewhere", src: "PORT=4000"},
+		{name: "longer number", src: "timeout: 40000\nid := 14000", own: true},
+		{name: "unrelated number", src: "const limit = 4000 // rows", own: true},
+		{name: "unrelated number in a sentence", src: "# allow up to 4000 requests", own: true},
+		{name: "decimal", src: "ratio := 4000.5", own: true},
+		{name: "context on another line", src: "port:\n  4000", own: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if tt.count == 0 {
+				want = tt.src
+			}
+			got, count := replacePort([]byte(tt.src), "4000", "4001", tt.own)
+			if string(got) != want || count != tt.count {
+				t.Errorf("replacePort(%q) = %q, %d; want %q, %d", tt.src, got, count, want, tt.count)
+			}
+		})
+	}
+}
+
+func TestBindings(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "docker-compose.yml", `services:
+  core:
+    ports:
+      - "4000:4000"
+      - 127.0.0.1:5432:5432 # postgres
+  web:
+    ports:
+      - target: 3000
+        published: "3001"
+    environment:
+      - PORT=3000
+`)
+	write(t, root, "docker-compose.billing.yml", "services:\n  billing:\n    ports:\n      - 4001:4000/tcp\n")
+	write(t, root, "compose.override.yml", "services:\n  core:\n    ports:\n      - 9999:9999\n")
+
+	got, err := Bindings(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Binding{
+		{File: "docker-compose.billing.yml", Line: 4, Port: "4001"},
+		{File: "docker-compose.yml", Line: 4, Port: "4000"},
+		{File: "docker-compose.yml", Line: 5, Port: "5432"},
+		{File: "docker-compose.yml", Line: 9, Port: "3001"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Bindings() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "docker-compose.billing.yml", "services:\n  billing:\n    ports:\n      - 4000:4000\n    environment:\n      - PORT=4000\n")
+	write(t, root, "app/service-billing/main.go", "package main\n\nconst addr = \":4000\"\nconst limit = 4000\n")
+	write(t, root, "app/service-web/.env", "BILLING_URL=http://localhost:4000\nPORT=4000\n")
+	write(t, root, "gofast.json", `{"services": [{"name": "billing", "port": "4000"}]}`)
+
+	files := Files{Compose: "docker-compose.billing.yml", Dir: "app/service-billing"}
+	changes, err := Rewrite(root, files, "4000", "4002", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Path: "app/service-billing/main.go", Count: 1},
+		{Path: "app/service-web/.env", Count: 1},
+		{Path: "docker-compose.billing.yml", Count: 3},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Rewrite() = %+v, want %+v", changes, want)
+	}
+	for path, content := range map[string]string{
+		"docker-compose.billing.yml":  "services:\n  billing:\n    ports:\n      - 4002:4002\n    environment:\n      - PORT=4002\n",
+		"app/service-billing/main.go": "package main\n\nconst addr = \":4002\"\nconst limit = 4000\n",
+		"app/service-web/.env":        "BILLING_URL=http://localhost:4002\nPORT=4000\n",
+		"gofast.json":                 `{"services": [{"name": "billing", "port": "4000"}]}`,
+	} {
+		if got := read(t, root, path); got != content {
+			t.Errorf("%s = %q, want %q", path, got, content)
+		}
+	}
+
+	// With the port shared, URLs of other services are ambiguous.
+	changes, err = Rewrite(root, files, "4002", "4003", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, root, "app/service-web/.env"); got != "BILLING_URL=http://localhost:4002\nPORT=4000\n" {
+		t.Errorf("shared Rewrite() changed app/service-web/.env: %q (changes %+v)", got, changes)
+	}
+}
+
+func TestFree(t *testing.T) {
+	tests := []struct {
+		preferred string
+		used      []string
+		want      string
+	}{
+		{"4000", nil, "4000"},
+		{"4000", []string{"4000", "4001", "4003"}, "4002"},
+		{"4000", []string{"3999"}, "4000"},
+	}
+	for _, tt := range tests {
+		if got := Free(tt.preferred, tt.used); got != tt.want {
+			t.Errorf("Free(%q, %v) = %q, want %q", tt.preferred, tt.used, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for port, ok := range map[string]bool{"1": true, "4000": true, "65535": true, "0": false, "65536": false, "04000": false, "abc": false, "": false} {
+		if err := Check(port); (err == nil) != ok {
+			t.Errorf("Check(%q) = %v, want ok %v", port, err, ok)
+		}
+	}
+}
+
+func write(t *testing.T, root, path, content string) {
+	t.Helper()
+	path = filepath.Join(root, filepath.FromSlash(path))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, root, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,336 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+)
+
+// Template is an extracted template tree. Dir must be treated as read-only:
+// it is shared by every project using that version.
+type Template struct {
+	Version  string
+	Checksum string // sha256 of the archive or commit of a git template; empty for a directory
+	Dir      string
+}
+
+// CacheEntry describes one cached template in index.json.
+type CacheEntry struct {
+	Version      string    `json:"version"`
+	Checksum     string    `json:"checksum"`
+	Size         int64     `json:"size"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+// CacheDir is where templates are cached: one directory per archive
+// checksum plus index.json mapping versions to checksums.
+func CacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gofast", "templates"), nil
+}
+
+// Fetch returns the template for version (empty: the latest), downloading
+// and caching it unless that version is already cached. A custom template
+// (--template, GOF_TEMPLATE) replaces the server's, and in offline mode the
+// local checkout is used. The template is validated before it is returned.
+func Fetch(email string, apiKey string, version string) (Template, error) {
+	t, err := resolve(email, apiKey, version)
+	if err != nil {
+		return Template{}, &DownloadError{Err: err}
+	}
+	if err := validateTemplate(t.Dir); err != nil {
+		return Template{}, &DownloadError{Err: err}
+	}
+	return t, nil
+}
+
+func resolve(email string, apiKey string, version string) (Template, error) {
+	if src := config.TemplateSource(); src != "" {
+		return fetchSource(src)
+	}
+	if config.Offline() {
+		dir, err := LocalTemplateDir()
+		if err != nil {
+			return Template{}, err
+		}
+		return Template{Version: LocalVersion, Dir: dir}, nil
+	}
+	return fetch(email, apiKey, version)
+}
+
+func fetch(email string, apiKey string, version string) (Template, error) {
+	root, index, err := openCache()
+	if err != nil {
+		return Template{}, err
+	}
+
+	if version != "" {
+		if e, ok := findVersion(index, version); ok {
+			dir := filepath.Join(root, e.Checksum)
+			if _, err := os.Stat(dir); err == nil {
+				return record(root, index, Template{Version: e.Version, Checksum: e.Checksum, Dir: dir}, e.Size)
+			}
+		}
+	}
+
+	tmp, err := os.MkdirTemp(root, ".download-*")
+	if err != nil {
+		return Template{}, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	zipPath := filepath.Join(tmp, "gofast-app.zip")
+	checksum, size, err := getFile(email, apiKey, version, zipPath)
+	if err != nil {
+		return Template{}, fmt.Errorf("error getting file: %w", err)
+	}
+	t, err := storeArchive(root, index, zipPath, checksum, size)
+	if err != nil {
+		return Template{}, err
+	}
+	if version != "" && t.Version != version {
+		return Template{}, fmt.Errorf("template version %s is not available (server returned %s)", version, t.Version)
+	}
+	return t, nil
+}
+
+// openCache creates the cache directory if needed and reads its index.
+func openCache() (string, []*CacheEntry, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", nil, fmt.Errorf("error creating template cache: %w", err)
+	}
+	index, err := readIndex(root)
+	if err != nil {
+		return "", nil, err
+	}
+	return root, index, nil
+}
+
+// storeArchive unpacks the archive at zipPath into the cache under its
+// checksum, unless that tree is already cached, and records it in the index.
+func storeArchive(root string, index []*CacheEntry, zipPath string, checksum string, size int64) (Template, error) {
+	dir := filepath.Join(root, checksum)
+	if e, ok := findChecksum(index, checksum); ok {
+		if _, err := os.Stat(dir); err == nil {
+			return record(root, index, Template{Version: e.Version, Checksum: checksum, Dir: dir}, size)
+		}
+	}
+
+	tmp, err := os.MkdirTemp(root, ".download-*")
+	if err != nil {
+		return Template{}, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	extracted := filepath.Join(tmp, "tree")
+	top, err := unzipFile(zipPath, extracted)
+	if err != nil {
+		return Template{}, fmt.Errorf("error unzipping file: %w", err)
+	}
+	if err := storeTree(extracted, dir); err != nil {
+		return Template{}, err
+	}
+	return record(root, index, Template{Version: versionFromFolder(top, checksum), Checksum: checksum, Dir: dir}, size)
+}
+
+// storeTree moves an extracted tree to its place in the cache. Another gof
+// process may have stored the same tree meanwhile; its copy is kept.
+func storeTree(src string, dir string) error {
+	if err := os.Rename(src, dir); err != nil {
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return nil
+		}
+		return fmt.Errorf("error storing template in cache: %w", err)
+	}
+	return nil
+}
+
+// record marks t as used now in the index, adding or updating its entry.
+func record(root string, index []*CacheEntry, t Template, size int64) (Template, error) {
+	now := time.Now()
+	e, ok := findVersion(index, t.Version)
+	if !ok {
+		e = &CacheEntry{Version: t.Version}
+		index = append(index, e)
+	}
+	if e.Checksum != t.Checksum {
+		e.Checksum, e.DownloadedAt = t.Checksum, now
+	}
+	e.Size, e.LastUsedAt = size, now
+	if err := writeIndex(root, index); err != nil {
+		return Template{}, err
+	}
+	return t, nil
+}
+
+// versionFromFolder derives the template version from the archive's
+// top-level folder (gofast-live-gofast-app-<commit>). Archives without that
+// layout are identified by their checksum.
+func versionFromFolder(top string, checksum string) string {
+	if v := strings.TrimPrefix(top, archivePrefix); v != top && v != "" {
+		return v
+	}
+	return "sha256-" + checksum[:12]
+}
+
+func fileChecksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("error hashing archive: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func findVersion(index []*CacheEntry, version string) (*CacheEntry, bool) {
+	for _, e := range index {
+		if e.Version == version {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func findChecksum(index []*CacheEntry, checksum string) (*CacheEntry, bool) {
+	for _, e := range index {
+		if e.Checksum == checksum {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func readIndex(root string) ([]*CacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(root, "index.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var index []*CacheEntry
+	if err := json.Unmarshal(data, &index); err != nil {
+		// A corrupt index only loses the version mapping; the trees are
+		// re-downloaded on demand.
+		return nil, nil
+	}
+	return index, nil
+}
+
+func writeIndex(root string, index []*CacheEntry) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	// A temp file per writer: concurrent gof processes each replace the
+	// index atomically and never see a half-written one.
+	tmp, err := os.CreateTemp(root, ".index-*")
+	if err != nil {
+		return fmt.Errorf("error writing template cache index: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing template cache index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing template cache index: %w", err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(root, "index.json"))
+}
+
+// CachedTemplates lists the cached templates, most recently used first.
+func CachedTemplates() ([]CacheEntry, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	index, err := readIndex(root)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CacheEntry, 0, len(index))
+	for _, e := range index {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsedAt.After(entries[j].LastUsedAt) })
+	return entries, nil
+}
+
+// PruneCache removes cached templates except the keep most recently used
+// ones and any version listed in pinned. It also removes trees no index
+// entry points to. It returns the removed entries.
+func PruneCache(keep int, pinned []string) ([]CacheEntry, error) {
+	root, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+	entries, err := CachedTemplates()
+	if err != nil {
+		return nil, err
+	}
+	var kept, removed []CacheEntry
+	for i, e := range entries {
+		if i < keep || containsString(pinned, e.Version) {
+			kept = append(kept, e)
+			continue
+		}
+		removed = append(removed, e)
+	}
+
+	inUse := map[string]bool{}
+	index := make([]*CacheEntry, 0, len(kept))
+	for i := range kept {
+		inUse[kept[i].Checksum] = true
+		index = append(index, &kept[i])
+	}
+	if err := writeIndex(root, index); err != nil {
+		return nil, err
+	}
+	dirs, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		// Dot directories are downloads in progress.
+		if d.IsDir() && !inUse[d.Name()] && !strings.HasPrefix(d.Name(), ".") {
+			if err := os.RemoveAll(filepath.Join(root, d.Name())); err != nil {
+				return removed, err
+			}
+		}
+	}
+	return removed, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
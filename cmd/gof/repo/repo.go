@@ -2,73 +2,106 @@ package repo
 
 import (
 	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/gofast-live/gofast-cli/v2/cmd/gof/config"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/httpx"
 )
 
-func DownloadRepo(email string, apiKey string, projectName string) error {
-	if os.Getenv("TEST") == "true" {
-		cmd := exec.Command("cp", "-r", "/home/mat/projects/gofast-app", projectName)
-		err := cmd.Run()
-		if err != nil {
-			return fmt.Errorf("error copying test app: %w", err)
-		}
-		return nil
-	}
-	// get the file
-	err := getFile(email, apiKey)
+// LocalVersion is the template version reported in offline mode, where the
+// template is a local checkout rather than a versioned archive.
+const LocalVersion = "local"
+
+// ServerVersion reports whether version names a template the GoFast server
+// can serve. Offline checkouts ("local"), git templates ("git-<commit>")
+// and archives without a version folder ("sha256-<checksum>") can only be
+// fetched again from their source.
+func ServerVersion(version string) bool {
+	return version != LocalVersion && !strings.HasPrefix(version, "git-") && !strings.HasPrefix(version, "sha256-")
+}
+
+// archivePrefix is the top-level folder of the server's template archive,
+// followed by the template commit, which serves as the template version.
+const archivePrefix = "gofast-live-gofast-app-"
+
+// DownloadError marks a failure to fetch or unpack the template, so callers
+// can tell it apart from errors in the work done with the template.
+type DownloadError struct {
+	Err error
+}
+
+func (e *DownloadError) Error() string { return e.Err.Error() }
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// DownloadRepo copies the template into projectName (relative to the working
+// directory). version pins the template; empty means the latest. It returns
+// the version that was used.
+func DownloadRepo(email string, apiKey string, version string, projectName string) (string, error) {
+	t, err := Fetch(email, apiKey, version)
 	if err != nil {
-		return fmt.Errorf("error getting file: %w", err)
+		return "", err
 	}
-	// unzip the file
-	err = unzipFile()
-	if err != nil {
-		return fmt.Errorf("error unzipping file: %w", err)
+	if err := copyTree(t.Dir, projectName); err != nil {
+		return "", &DownloadError{Err: fmt.Errorf("error copying template: %w", err)}
 	}
-	// remove the zip file
-	err = os.Remove("gofast-app.zip")
+	return t.Version, nil
+}
+
+// LocalTemplateDir is the gofast-app checkout offline mode copies from when
+// no --template is given: the nearest gofast-app directory next to the
+// working directory or one of its parents (../gofast-app from the CLI repo,
+// ../../gofast-app from a demo project inside it).
+func LocalTemplateDir() (string, error) {
+	dir, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("error removing zip file: %w", err)
+		return "", err
 	}
-	// find and rename the folder `gofast-live-gofast-app-...` to the project name
-	files, err := os.ReadDir(".")
-	if err != nil {
-		return fmt.Errorf("error reading current directory: %w", err)
-	}
-	for _, f := range files {
-		if f.IsDir() {
-			if strings.HasPrefix(f.Name(), "gofast-live-gofast-app-") {
-				err = os.Rename(f.Name(), projectName)
-				if err != nil {
-					return err
-				}
-				break
-			}
+	for {
+		parent := filepath.Dir(dir)
+		candidate := filepath.Join(parent, "gofast-app")
+		if fi, err := os.Stat(candidate); err == nil && fi.IsDir() {
+			return candidate, nil
 		}
+		if parent == dir {
+			return "", errors.New("offline mode: no gofast-app checkout found next to the working directory or its parents; use --template")
+		}
+		dir = parent
 	}
-
-	return nil
 }
 
-func getFile(email string, apiKey string) error {
-	client := http.Client{}
-	req, err := http.NewRequest("GET", config.SERVER_URL+"/v2?email="+email, nil)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+// Limits on what a template archive may expand to. The real template is a
+// few MB; anything near these is a broken or hostile download.
+const (
+	maxArchiveSize = 256 << 20 // compressed, as downloaded
+	maxTreeSize    = 1 << 30   // all extracted files together
+)
+
+// checksumHeader optionally carries the hex sha256 of the archive; when the
+// server sends it, the download is verified against it.
+const checksumHeader = "X-Checksum-Sha256"
+
+// getFile streams the template archive to zipPath and returns its sha256 and
+// size. A pinned version is sent along so the server can serve that release.
+// Oversized, truncated or corrupted downloads are rejected.
+func getFile(email string, apiKey string, version string, zipPath string) (string, int64, error) {
+	query := url.Values{"email": {email}}
+	if version != "" {
+		query.Set("version", version)
 	}
-	req.Header.Set("Authorization", "bearer "+apiKey)
-	resp, err := client.Do(req)
+	header := http.Header{}
+	header.Set("Authorization", "bearer "+apiKey)
+	resp, err := httpx.Get(config.ServerURL()+"/v2?"+query.Encode(), header)
 	if err != nil {
-		return fmt.Errorf("error making request: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error downloading file: %s", resp.Status)
+		return "", 0, err
 	}
 	defer func() {
 		err := resp.Body.Close()
@@ -77,35 +110,47 @@ func getFile(email string, apiKey string) error {
 		}
 	}()
 
-	// save the file to the disk
-	_, err = os.Create("gofast-app.zip")
-	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("error downloading file: %s", resp.Status)
+	}
+	if resp.ContentLength > maxArchiveSize {
+		return "", 0, fmt.Errorf("template archive is too large (%d bytes, limit %d)", resp.ContentLength, maxArchiveSize)
 	}
-	file, err := os.OpenFile("gofast-app.zip", os.O_WRONLY, 0644)
+
+	file, err := os.OpenFile(zipPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
 	if err != nil {
-		return fmt.Errorf("error opening file: %w", err)
+		return "", 0, fmt.Errorf("error creating file: %w", err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, h), io.LimitReader(resp.Body, maxArchiveSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
 	}
-	defer func() {
-		err := file.Close()
-		if err != nil {
-			fmt.Printf("error closing file: %v\n", err)
-		}
-	}()
-	_, err = io.Copy(file, resp.Body)
 	if err != nil {
-		return fmt.Errorf("error copying response body to file: %w", err)
+		return "", 0, fmt.Errorf("error saving template archive: %w", err)
+	}
+	if n > maxArchiveSize {
+		return "", 0, fmt.Errorf("template archive is larger than %d bytes", maxArchiveSize)
 	}
-	return nil
+	if resp.ContentLength >= 0 && n != resp.ContentLength {
+		return "", 0, fmt.Errorf("template download truncated: got %d of %d bytes", n, resp.ContentLength)
+	}
+	checksum := hex.EncodeToString(h.Sum(nil))
+	if want := resp.Header.Get(checksumHeader); want != "" && !strings.EqualFold(want, checksum) {
+		return "", 0, fmt.Errorf("template checksum mismatch: got %s, server says %s", checksum, want)
+	}
+	return checksum, n, nil
 }
 
-func unzipFile() error {
-	if os.Getenv("TEST") == "true" {
-		return nil
-	}
-	archive, err := zip.OpenReader("gofast-app.zip")
+// unzipFile extracts the archive into destDir. When every entry sits in one
+// top-level folder, as in the server's archive, that folder is dropped and
+// its name returned; otherwise the archive is extracted as is. Entries that
+// would land outside destDir (absolute paths, "..", symlinks pointing out)
+// are rejected, and so is an archive that expands past maxTreeSize.
+func unzipFile(zipPath string, destDir string) (string, error) {
+	archive, err := zip.OpenReader(zipPath)
 	if err != nil {
-		return fmt.Errorf("error opening zip file: %w", err)
+		return "", fmt.Errorf("error opening zip file: %w", err)
 	}
 	defer func() {
 		err := archive.Close()
@@ -113,42 +158,193 @@ func unzipFile() error {
 			fmt.Printf("error closing archive: %v\n", err)
 		}
 	}()
+
+	top := archiveTop(archive.File)
+	budget := int64(maxTreeSize)
+	// Symlinks are created after every file, so no entry is written through
+	// a link the archive planted.
+	var links []*zip.File
+	var linkPaths []string
 	for _, file := range archive.File {
-		src, err := file.Open()
-		if err != nil {
-			return fmt.Errorf("error opening file in zip: %w", err)
+		name := strings.TrimPrefix(file.Name, "./")
+		if top != "" {
+			_, name, _ = strings.Cut(name, "/")
+		}
+		name = strings.TrimSuffix(name, "/")
+		if name == "" {
+			continue
+		}
+		rel := filepath.FromSlash(name)
+		if !filepath.IsLocal(rel) || strings.Contains(name, `\`) {
+			return "", fmt.Errorf("unsafe path in archive: %s", file.Name)
 		}
+		target := filepath.Join(destDir, rel)
 
-		defer func() {
-			err := src.Close()
-			if err != nil {
-				fmt.Printf("error closing source file: %v\n", err)
+		switch mode := file.Mode(); {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return "", fmt.Errorf("error creating directory: %w", err)
 			}
-		}()
-
-		if file.FileInfo().IsDir() {
-			err := os.MkdirAll(file.Name, os.ModePerm)
+		case mode&os.ModeSymlink != 0:
+			links = append(links, file)
+			linkPaths = append(linkPaths, rel)
+		case mode.IsRegular():
+			n, err := extractFile(file, target, budget)
 			if err != nil {
-				return fmt.Errorf("error creating directory: %w", err)
+				return "", err
 			}
-			continue
+			budget -= n
+		default:
+			return "", fmt.Errorf("unsupported entry in archive: %s", file.Name)
 		}
+	}
+	for i, file := range links {
+		if err := extractSymlink(file, destDir, linkPaths[i]); err != nil {
+			return "", err
+		}
+	}
+	// A link can be lexically inside the tree and still resolve outside it
+	// through another link; check where each one really points.
+	root, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return "", err
+	}
+	for i, rel := range linkPaths {
+		resolved, err := filepath.EvalSymlinks(filepath.Join(destDir, rel))
+		if err != nil {
+			return "", fmt.Errorf("unsafe symlink in archive: %s: %w", links[i].Name, err)
+		}
+		if inside, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(inside) {
+			return "", fmt.Errorf("unsafe symlink in archive: %s points outside the template", links[i].Name)
+		}
+	}
+	return top, nil
+}
+
+// archiveTop returns the folder every entry of the archive sits in, or "" if
+// there is none.
+func archiveTop(files []*zip.File) string {
+	var top string
+	for _, file := range files {
+		first, rest, found := strings.Cut(strings.TrimPrefix(file.Name, "./"), "/")
+		if !found || (rest == "" && !file.FileInfo().IsDir()) {
+			return ""
+		}
+		if top != "" && first != top {
+			return ""
+		}
+		top = first
+	}
+	return top
+}
+
+// extractFile writes one archive entry to target, failing once more than
+// budget bytes have been written. It returns the bytes written.
+func extractFile(file *zip.File, target string, budget int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, fmt.Errorf("error creating directory: %w", err)
+	}
+	src, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("error opening file in zip: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	mode := file.Mode().Perm()
+	if mode == 0 {
+		mode = 0o644
+	}
+	// O_EXCL: an entry never overwrites an earlier one, or follows a symlink
+	// an earlier entry planted.
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return 0, fmt.Errorf("error creating destination file: %w", err)
+	}
+	n, err := io.Copy(dst, io.LimitReader(src, budget+1))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, fmt.Errorf("error copying file from zip: %w", err)
+	}
+	if n > budget {
+		return n, fmt.Errorf("template archive expands to more than %d bytes", maxTreeSize)
+	}
+	return n, nil
+}
+
+// extractSymlink recreates a symlink entry at rel inside destDir. Its target
+// must be relative and stay inside the tree, and no parent of rel may itself
+// be a symlink.
+func extractSymlink(file *zip.File, destDir string, rel string) error {
+	src, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("error opening file in zip: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+	link, err := io.ReadAll(io.LimitReader(src, 4096))
+	if err != nil {
+		return fmt.Errorf("error reading symlink from zip: %w", err)
+	}
+	dest := filepath.FromSlash(string(link))
+	if filepath.IsAbs(dest) || !filepath.IsLocal(filepath.Join(filepath.Dir(rel), dest)) {
+		return fmt.Errorf("unsafe symlink in archive: %s -> %s", file.Name, link)
+	}
+	for dir := filepath.Dir(rel); dir != "."; dir = filepath.Dir(dir) {
+		if fi, err := os.Lstat(filepath.Join(destDir, dir)); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("unsafe symlink in archive: %s is inside another symlink", file.Name)
+		}
+	}
+	target := filepath.Join(destDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	return os.Symlink(dest, target)
+}
 
-		dst, err := os.Create(file.Name)
+// copyTree copies the directory src to dst, which must not exist yet.
+func copyTree(src string, dst string) error {
+	if _, err := os.Stat(dst); err == nil {
+		return fmt.Errorf("%s already exists", dst)
+	}
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
 		if err != nil {
-			return fmt.Errorf("error creating destination file: %w", err)
+			return err
 		}
-		defer func() {
-			err := dst.Close()
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
 			if err != nil {
-				fmt.Printf("error closing destination file: %v\n", err)
+				return err
 			}
-		}()
-
-		_, err = io.Copy(dst, src)
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil
+		}
+		in, err := os.Open(path)
 		if err != nil {
-			return fmt.Errorf("error copying file from zip: %w", err)
+			return err
 		}
-	}
-	return nil
+		defer func() { _ = in.Close() }()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm()|0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			_ = out.Close()
+			return err
+		}
+		return out.Close()
+	})
 }
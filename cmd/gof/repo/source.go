@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+)
+
+// gitPrefixes mark a template source as a git remote.
+var gitPrefixes = []string{"https://", "http://", "ssh://", "git://", "git@", "file://"}
+
+// fetchSource loads a custom template: a local directory (used in place), a
+// zip file or a git remote with an optional #ref (both cached like server
+// templates).
+func fetchSource(src string) (Template, error) {
+	for _, prefix := range gitPrefixes {
+		if strings.HasPrefix(src, prefix) {
+			return fetchGit(src)
+		}
+	}
+	path, err := filepath.Abs(src)
+	if err != nil {
+		return Template{}, err
+	}
+	if strings.Contains(path, "#") || strings.HasSuffix(path, ".git") {
+		return fetchGit(path)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Template{}, fmt.Errorf("template %s: %w", src, err)
+	}
+	if fi.IsDir() {
+		return Template{Version: LocalVersion, Dir: path}, nil
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return Template{}, fmt.Errorf("template %s is not a directory, zip file or git URL", src)
+	}
+	checksum, size, err := fileChecksum(path)
+	if err != nil {
+		return Template{}, err
+	}
+	root, index, err := openCache()
+	if err != nil {
+		return Template{}, err
+	}
+	return storeArchive(root, index, path, checksum, size)
+}
+
+// fetchGit makes a shallow fetch of remote#ref (default HEAD) and caches the
+// checked out tree under the commit hash, so the same commit is extracted
+// only once.
+func fetchGit(src string) (Template, error) {
+	remote, ref, _ := strings.Cut(src, "#")
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return Template{}, fmt.Errorf("git is required for template %s", src)
+	}
+	root, index, err := openCache()
+	if err != nil {
+		return Template{}, err
+	}
+	tmp, err := os.MkdirTemp(root, ".git-*")
+	if err != nil {
+		return Template{}, fmt.Errorf("error creating temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	if _, err := git(tmp, "init", "-q"); err != nil {
+		return Template{}, err
+	}
+	if _, err := git(tmp, "fetch", "-q", "--depth", "1", remote, ref); err != nil {
+		return Template{}, err
+	}
+	commit, err := git(tmp, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return Template{}, err
+	}
+	dir := filepath.Join(root, commit)
+	if _, err := os.Stat(dir); err != nil {
+		if _, err := git(tmp, "checkout", "-q", "FETCH_HEAD"); err != nil {
+			return Template{}, err
+		}
+		if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+			return Template{}, err
+		}
+		if err := storeTree(tmp, dir); err != nil {
+			return Template{}, err
+		}
+	}
+	return record(root, index, Template{Version: "git-" + commit[:12], Checksum: commit, Dir: dir}, treeSize(dir))
+}
+
+// git runs a git command in dir without prompting for credentials and
+// returns its trimmed output.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func treeSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// validateTemplate fails when the template lacks a file or marker generation
+// needs (see markers.CheckTemplate). Warnings are ignored: generation falls
+// back to other merge points for those.
+func validateTemplate(dir string) error {
+	problems, err := markers.CheckTemplate(dir)
+	if err != nil {
+		return fmt.Errorf("validating template %s: %w", dir, err)
+	}
+	if !markers.HasFailures(problems) {
+		return nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid template %s:", dir)
+	for _, p := range problems {
+		if p.Severity == markers.Failure {
+			sb.WriteString("\n  " + p.String())
+		}
+	}
+	return errors.New(sb.String())
+}
@@ -5,10 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/gertd/go-pluralize"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/naming"
 )
 
 type Column struct {
@@ -23,43 +24,6 @@ func GetModelPath(modelName string) string {
 	return "/models/" + pluralizeClient.Plural(modelName)
 }
 
-// toCamelCase converts snake_case to camelCase (e.g., "published_at" -> "publishedAt")
-// This is needed because protobuf-generated TypeScript uses camelCase field names.
-func toCamelCase(s string) string {
-	parts := strings.Split(s, "_")
-	if len(parts) == 1 {
-		return s
-	}
-	var b strings.Builder
-	b.WriteString(parts[0])
-	for _, p := range parts[1:] {
-		if p == "" {
-			continue
-		}
-		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
-	}
-	return b.String()
-}
-
-// toPascalCase converts snake_case to PascalCase (e.g., "user_profile" -> "UserProfile")
-// This is needed because protobuf-generated service names use PascalCase.
-func toPascalCase(s string) string {
-	parts := strings.Split(s, "_")
-	var b strings.Builder
-	for _, p := range parts {
-		if p == "" {
-			continue
-		}
-		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
-	}
-	return b.String()
-}
-
-func replaceProtoFieldAccess(content, fieldName, replacement string) string {
-	pattern := regexp.MustCompile(`\.` + regexp.QuoteMeta(fieldName) + `\b`)
-	return pattern.ReplaceAllString(content, "."+replacement)
-}
-
 func GenerateSvelteScaffolding(modelName string, columns []Column) error {
 	if err := generateClientConnect(modelName); err != nil {
 		return fmt.Errorf("generating client connect.ts: %w", err)
@@ -73,9 +37,12 @@ func GenerateSvelteScaffolding(modelName string, columns []Column) error {
 	return nil
 }
 
-func FormatProject() error {
-	cmd := "cd ./app/service-svelte && npm ci && npm run format"
+// FormatProject installs the client's dependencies and formats it. root is
+// the project directory.
+func FormatProject(root string) error {
+	cmd := "npm ci && npm run format"
 	execCmd := exec.Command("bash", "-c", cmd)
+	execCmd.Dir = filepath.Join(root, "app", "service-svelte")
 	out, err := execCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("running npm commands: %w\nOutput: %s", err, string(out))
@@ -94,7 +61,7 @@ func generateClientConnect(modelName string) error {
 	s := string(b)
 
 	// Use PascalCase for service name (protobuf generates UserProfileService, not User_profileService)
-	pascalName := toPascalCase(modelName)
+	pascalName := naming.Pascal(modelName)
 	serviceToken := pascalName + "Service"
 	clientExport := "export const " + modelName + "_client = createClient(" + serviceToken + ", transport);"
 
@@ -154,13 +121,13 @@ func generateClientConnect(modelName string) error {
 
 // generateClientListPage scaffolds a client list page by copying the
 // skeleton list Svelte file and performing token replacements for
-// singular/plural model variants. Columns are not yet expanded; this
-// is a straight token-based clone of the skeleton UI.
+// singular/plural model variants. It fills the GF_LIST_HEADERS and
+// GF_LIST_CELLS regions with one column per model column.
 func generateClientListPage(modelName string, columns []Column) error {
 	sourcePath := "./app/service-svelte/src/routes/(app)/models/skeletons/+page.svelte"
 	pluralLower := pluralizeClient.Plural(modelName)
-	pluralCap := toPascalCase(pluralLower)
-	capitalizedModelName := toPascalCase(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
 
 	// Ensure destination directory exists
 	destDir := filepath.Join("app/service-svelte/src/routes/(app)/models", pluralLower)
@@ -181,27 +148,15 @@ func generateClientListPage(modelName string, columns []Column) error {
 	s = strings.ReplaceAll(s, "skeletons", pluralLower)
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
 	// Replace protobuf field access patterns with camelCase BEFORE the blanket replacement.
-	camelName := toCamelCase(modelName)
-	s = replaceProtoFieldAccess(s, "skeleton", camelName)
+	camelName := naming.Camel(modelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
 	s = strings.ReplaceAll(s, "skeleton", modelName)
 
-	// Helper: Title-case a label from snake_case
-	toTitle := func(name string) string {
-		parts := strings.Split(name, "_")
-		for i := range parts {
-			if parts[i] == "" {
-				continue
-			}
-			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
-		}
-		return strings.Join(parts, " ")
-	}
-
 	// Build headers: per model columns + Created/Updated
 	var h strings.Builder
 	for _, c := range columns {
 		h.WriteString("                <th role=\"columnheader\">")
-		h.WriteString(toTitle(c.Name))
+		h.WriteString(naming.Title(c.Name))
 		h.WriteString("</th>\n")
 	}
 	h.WriteString("                <th role=\"columnheader\">Created</th>\n")
@@ -212,7 +167,7 @@ func generateClientListPage(modelName string, columns []Column) error {
 	// Use camelCase for proto field access (protobuf-generated TS uses camelCase)
 	var b strings.Builder
 	for _, c := range columns {
-		field := toCamelCase(c.Name)
+		field := naming.Camel(c.Name)
 		switch c.Type {
 		case "date":
 			b.WriteString("                        <td>{new Date(" + modelName + "." + field + ").toLocaleDateString()}</td>\n")
@@ -226,49 +181,16 @@ func generateClientListPage(modelName string, columns []Column) error {
 	b.WriteString("                        <td>{new Date(" + modelName + ".updated).toLocaleDateString()}</td>\n")
 	cells := b.String()
 
-	// Replace regions delimited by markers
-	replaceRegion := func(content, startMarker, endMarker, replacement string) (string, error) {
-		start := strings.Index(content, startMarker)
-		end := strings.Index(content, endMarker)
-		if start == -1 || end == -1 || end < start {
-			return content, fmt.Errorf("markers %q .. %q not found", startMarker, endMarker)
-		}
-		start += len(startMarker)
-		return content[:start] + "\n" + replacement + content[end:], nil
-	}
-
 	var rErr error
-	s, rErr = replaceRegion(s, "<!-- GF_LIST_HEADERS_START -->", "<!-- GF_LIST_HEADERS_END -->", headers)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_HEADERS", headers)
 	if rErr != nil {
 		return fmt.Errorf("replacing headers: %w", rErr)
 	}
-	s, rErr = replaceRegion(s, "<!-- GF_LIST_CELLS_START -->", "<!-- GF_LIST_CELLS_END -->", cells)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_CELLS", cells)
 	if rErr != nil {
 		return fmt.Errorf("replacing cells: %w", rErr)
 	}
 
-	// Remove lines that contain marker comments to avoid extra spacing
-	markers := []string{
-		"<!-- GF_LIST_HEADERS_START -->",
-		"<!-- GF_LIST_HEADERS_END -->",
-		"<!-- GF_LIST_CELLS_START -->",
-		"<!-- GF_LIST_CELLS_END -->",
-	}
-	var outLines []string
-	for line := range strings.SplitSeq(s, "\n") {
-		skip := false
-		for _, m := range markers {
-			if strings.Contains(line, m) {
-				skip = true
-				break
-			}
-		}
-		if !skip {
-			outLines = append(outLines, line)
-		}
-	}
-	s = strings.Join(outLines, "\n")
-
 	// Write out the generated file
 	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
 		return fmt.Errorf("writing client list page %s: %w", destPath, err)
@@ -283,8 +205,8 @@ func generateClientListPage(modelName string, columns []Column) error {
 func generateClientDetailPage(modelName string, columns []Column) error {
 	sourcePath := "./app/service-svelte/src/routes/(app)/models/skeletons/[skeleton_id]/+page.svelte"
 	pluralLower := pluralizeClient.Plural(modelName)
-	pluralCap := toPascalCase(pluralLower)
-	capitalizedModelName := toPascalCase(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
 
 	// Ensure destination directory exists: /(app)/models/<plural>/[<model>_id]
 	destDir := filepath.Join(
@@ -308,9 +230,9 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
 	s = strings.ReplaceAll(s, "skeletons", pluralLower)
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
-	camelName := toCamelCase(modelName)
+	camelName := naming.Camel(modelName)
 	// Replace protobuf field access patterns with camelCase BEFORE the blanket replacement.
-	s = replaceProtoFieldAccess(s, "skeleton", camelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
 	s = strings.ReplaceAll(s, "skeleton: {", camelName+": {")
 	s = strings.ReplaceAll(s, "skeleton", modelName)
 
@@ -323,7 +245,7 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	emptyB.WriteString(emptyIndent + "updated: \"\",\n")
 	emptyB.WriteString(emptyIndent + "id: \"\",\n")
 	for _, c := range columns {
-		camelName := toCamelCase(c.Name)
+		camelName := naming.Camel(c.Name)
 		switch c.Type {
 		case "bool":
 			emptyB.WriteString(emptyIndent + camelName + ": false,\n")
@@ -338,7 +260,7 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	var fdB strings.Builder
 	fdIndent := "        "
 	for _, c := range columns {
-		camelName := toCamelCase(c.Name)
+		camelName := naming.Camel(c.Name)
 		if c.Type == "bool" {
 			fdB.WriteString(fdIndent + "const " + camelName + " = formData.get(\"" + c.Name + "\") === \"on\";\n")
 		} else {
@@ -351,27 +273,17 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	// Use camelCase for proto field names
 	var reqB strings.Builder
 	for _, c := range columns {
-		camelName := toCamelCase(c.Name)
+		camelName := naming.Camel(c.Name)
 		reqB.WriteString("                        " + camelName + ",\n")
 	}
 	payloadFields := strings.TrimRight(reqB.String(), "\n")
 
 	// 4) Form input fields markup
 	// Use snake_case for HTML id/name attributes, camelCase for proto field access
-	toTitle := func(name string) string {
-		parts := strings.Split(name, "_")
-		for i := range parts {
-			if parts[i] == "" {
-				continue
-			}
-			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
-		}
-		return strings.Join(parts, " ")
-	}
 	var uiB strings.Builder
 	for _, c := range columns {
-		label := toTitle(c.Name)
-		camelName := toCamelCase(c.Name)
+		label := naming.Title(c.Name)
+		camelName := naming.Camel(c.Name)
 		switch c.Type {
 		case "string":
 			uiB.WriteString("        <label class=\"label\" for=\"" + c.Name + "\">" + label + "</label>\n")
@@ -427,34 +339,24 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	}
 	fieldsSnippet := strings.TrimRight(uiB.String(), "\n")
 
-	// Replace regions delimited by markers
-	replaceRegion := func(content, startMarker, endMarker, replacement string) (string, error) {
-		start := strings.Index(content, startMarker)
-		end := strings.Index(content, endMarker)
-		if start == -1 || end == -1 || end < start {
-			return content, fmt.Errorf("markers %q .. %q not found", startMarker, endMarker)
-		}
-		return content[:start] + "\n" + replacement + "\n" + content[end+len(endMarker):], nil
-	}
-
 	var rErr error
-	s, rErr = replaceRegion(s, "// GF_DETAIL_EMPTY_START", "// GF_DETAIL_EMPTY_END", emptySnippet)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EMPTY", emptySnippet)
 	if rErr != nil {
 		return fmt.Errorf("replacing empty defaults: %w", rErr)
 	}
-	s, rErr = replaceRegion(s, "// GF_DETAIL_FORMDATA_START", "// GF_DETAIL_FORMDATA_END", formDataSnippet)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FORMDATA", formDataSnippet)
 	if rErr != nil {
 		return fmt.Errorf("replacing form data: %w", rErr)
 	}
-	s, rErr = replaceRegion(s, "// GF_DETAIL_CREATE_FIELDS_START", "// GF_DETAIL_CREATE_FIELDS_END", payloadFields)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_CREATE_FIELDS", payloadFields)
 	if rErr != nil {
 		return fmt.Errorf("replacing create fields: %w", rErr)
 	}
-	s, rErr = replaceRegion(s, "// GF_DETAIL_EDIT_FIELDS_START", "// GF_DETAIL_EDIT_FIELDS_END", payloadFields)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EDIT_FIELDS", payloadFields)
 	if rErr != nil {
 		return fmt.Errorf("replacing edit fields: %w", rErr)
 	}
-	s, rErr = replaceRegion(s, "<!-- GF_DETAIL_FIELDS_START -->", "<!-- GF_DETAIL_FIELDS_END -->", fieldsSnippet)
+	s, rErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FIELDS", fieldsSnippet)
 	if rErr != nil {
 		return fmt.Errorf("replacing UI fields: %w", rErr)
 	}
@@ -468,25 +370,11 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 		}
 	}
 
-	// Remove lines that contain marker comments to avoid extra spacing
-	markers := []string{
-		"// GF_DETAIL_EMPTY_START", "// GF_DETAIL_EMPTY_END",
-		"// GF_DETAIL_FORMDATA_START", "// GF_DETAIL_FORMDATA_END",
-		"// GF_DETAIL_CREATE_FIELDS_START", "// GF_DETAIL_CREATE_FIELDS_END",
-		"// GF_DETAIL_EDIT_FIELDS_START", "// GF_DETAIL_EDIT_FIELDS_END",
-		"<!-- GF_DETAIL_FIELDS_START -->", "<!-- GF_DETAIL_FIELDS_END -->",
-	}
 	var outLines []string
 	inFormatDateFunc := false
 	braceDepth := 0
 	for line := range strings.SplitSeq(s, "\n") {
 		skip := false
-		for _, m := range markers {
-			if strings.Contains(line, m) {
-				skip = true
-				break
-			}
-		}
 		// Remove formatDate function if no date columns
 		if !hasDateColumn {
 			trimmed := strings.TrimSpace(line)
@@ -5,10 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/gertd/go-pluralize"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/markers"
+	"github.com/gofast-live/gofast-cli/v2/cmd/gof/naming"
 )
 
 type Column struct {
@@ -22,9 +23,12 @@ func GetModelPath(modelName string) string {
 	return "/models/" + pluralizeClient.Plural(modelName)
 }
 
-func FormatProject() error {
-	cmd := `cd ./app/service-tanstack && npm ci && node --input-type=module -e "import { Generator } from '@tanstack/router-generator'; import { getConfig } from '@tanstack/router-plugin'; const root = process.cwd(); const generator = new Generator({ config: getConfig({}, root), root }); await generator.run();" && npm run format`
+// FormatProject installs the client's dependencies and formats it. root is
+// the project directory.
+func FormatProject(root string) error {
+	cmd := `npm ci && node --input-type=module -e "import { Generator } from '@tanstack/router-generator'; import { getConfig } from '@tanstack/router-plugin'; const root = process.cwd(); const generator = new Generator({ config: getConfig({}, root), root }); await generator.run();" && npm run format`
 	execCmd := exec.Command("bash", "-c", cmd)
+	execCmd.Dir = filepath.Join(root, "app", "service-tanstack")
 	out, err := execCmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("running npm commands: %w\nOutput: %s", err, string(out))
@@ -45,39 +49,6 @@ func GenerateTanstackScaffolding(modelName string, columns []Column) error {
 	return nil
 }
 
-func toCamelCase(s string) string {
-	parts := strings.Split(s, "_")
-	if len(parts) == 1 {
-		return s
-	}
-	var b strings.Builder
-	b.WriteString(parts[0])
-	for _, p := range parts[1:] {
-		if p == "" {
-			continue
-		}
-		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
-	}
-	return b.String()
-}
-
-func toPascalCase(s string) string {
-	parts := strings.Split(s, "_")
-	var b strings.Builder
-	for _, p := range parts {
-		if p == "" {
-			continue
-		}
-		b.WriteString(strings.ToUpper(p[:1]) + p[1:])
-	}
-	return b.String()
-}
-
-func replaceProtoFieldAccess(content, fieldName, replacement string) string {
-	pattern := regexp.MustCompile(`\.` + regexp.QuoteMeta(fieldName) + `\b`)
-	return pattern.ReplaceAllString(content, "."+replacement)
-}
-
 func generateClientConnect(modelName string) error {
 	path := "./app/service-tanstack/src/lib/connect.ts"
 	b, err := os.ReadFile(path)
@@ -86,7 +57,7 @@ func generateClientConnect(modelName string) error {
 	}
 	s := string(b)
 
-	pascalName := toPascalCase(modelName)
+	pascalName := naming.Pascal(modelName)
 	serviceToken := pascalName + "Service"
 	clientExport := "export const " + modelName + "_client = createClient(" + serviceToken + ", transport)"
 
@@ -137,8 +108,8 @@ func generateClientConnect(modelName string) error {
 func generateClientListPage(modelName string, columns []Column) error {
 	sourcePath := "./app/service-tanstack/src/routes/_layout/models/skeletons/index.tsx"
 	pluralLower := pluralizeClient.Plural(modelName)
-	pluralCap := toPascalCase(pluralLower)
-	capitalizedModelName := toPascalCase(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
 
 	destDir := filepath.Join("app/service-tanstack/src/routes/_layout/models", pluralLower)
 	if err := os.MkdirAll(destDir, 0o755); err != nil {
@@ -155,25 +126,14 @@ func generateClientListPage(modelName string, columns []Column) error {
 	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
 	s = strings.ReplaceAll(s, "skeletons", pluralLower)
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
-	camelName := toCamelCase(modelName)
-	s = replaceProtoFieldAccess(s, "skeleton", camelName)
+	camelName := naming.Camel(modelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
 	s = strings.ReplaceAll(s, "skeleton", modelName)
 
-	toTitle := func(name string) string {
-		parts := strings.Split(name, "_")
-		for i := range parts {
-			if parts[i] == "" {
-				continue
-			}
-			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
-		}
-		return strings.Join(parts, " ")
-	}
-
 	var headersBuilder strings.Builder
 	for _, c := range columns {
 		headersBuilder.WriteString("                <th role=\"columnheader\">")
-		headersBuilder.WriteString(toTitle(c.Name))
+		headersBuilder.WriteString(naming.Title(c.Name))
 		headersBuilder.WriteString("</th>\n")
 	}
 	headersBuilder.WriteString("                <th role=\"columnheader\">Created</th>\n")
@@ -181,7 +141,7 @@ func generateClientListPage(modelName string, columns []Column) error {
 
 	var cellsBuilder strings.Builder
 	for _, c := range columns {
-		field := toCamelCase(c.Name)
+		field := naming.Camel(c.Name)
 		switch c.Type {
 		case "date":
 			cellsBuilder.WriteString("                    <td>{new Date(" + modelName + "." + field + ").toLocaleDateString()}</td>\n")
@@ -194,47 +154,16 @@ func generateClientListPage(modelName string, columns []Column) error {
 	cellsBuilder.WriteString("                    <td>{new Date(" + modelName + ".created).toLocaleDateString()}</td>\n")
 	cellsBuilder.WriteString("                    <td>{new Date(" + modelName + ".updated).toLocaleDateString()}</td>\n")
 
-	replaceRegion := func(content, startMarker, endMarker, replacement string) (string, error) {
-		start := strings.Index(content, startMarker)
-		end := strings.Index(content, endMarker)
-		if start == -1 || end == -1 || end < start {
-			return content, fmt.Errorf("markers %q .. %q not found", startMarker, endMarker)
-		}
-		start += len(startMarker)
-		return content[:start] + "\n" + replacement + content[end:], nil
-	}
-
 	var replaceErr error
-	s, replaceErr = replaceRegion(s, "{/* GF_LIST_HEADERS_START */}", "{/* GF_LIST_HEADERS_END */}", headersBuilder.String())
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_HEADERS", headersBuilder.String())
 	if replaceErr != nil {
 		return fmt.Errorf("replacing headers: %w", replaceErr)
 	}
-	s, replaceErr = replaceRegion(s, "{/* GF_LIST_CELLS_START */}", "{/* GF_LIST_CELLS_END */}", cellsBuilder.String())
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "LIST_CELLS", cellsBuilder.String())
 	if replaceErr != nil {
 		return fmt.Errorf("replacing cells: %w", replaceErr)
 	}
 
-	markers := []string{
-		"{/* GF_LIST_HEADERS_START */}",
-		"{/* GF_LIST_HEADERS_END */}",
-		"{/* GF_LIST_CELLS_START */}",
-		"{/* GF_LIST_CELLS_END */}",
-	}
-	var outLines []string
-	for line := range strings.SplitSeq(s, "\n") {
-		skip := false
-		for _, marker := range markers {
-			if strings.Contains(line, marker) {
-				skip = true
-				break
-			}
-		}
-		if !skip {
-			outLines = append(outLines, line)
-		}
-	}
-	s = strings.Join(outLines, "\n")
-
 	if err := os.WriteFile(destPath, []byte(s), 0o644); err != nil {
 		return fmt.Errorf("writing client list page %s: %w", destPath, err)
 	}
@@ -244,8 +173,8 @@ func generateClientListPage(modelName string, columns []Column) error {
 func generateClientDetailPage(modelName string, columns []Column) error {
 	sourcePath := "./app/service-tanstack/src/routes/_layout/models/skeletons/$skeleton_id.tsx"
 	pluralLower := pluralizeClient.Plural(modelName)
-	pluralCap := toPascalCase(pluralLower)
-	capitalizedModelName := toPascalCase(modelName)
+	pluralCap := naming.Pascal(pluralLower)
+	capitalizedModelName := naming.Pascal(modelName)
 
 	destDir := filepath.Join("app/service-tanstack/src/routes/_layout/models", pluralLower)
 	if err := os.MkdirAll(destDir, 0o755); err != nil {
@@ -262,8 +191,8 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	s = strings.ReplaceAll(s, "Skeletons", pluralCap)
 	s = strings.ReplaceAll(s, "skeletons", pluralLower)
 	s = strings.ReplaceAll(s, "Skeleton", capitalizedModelName)
-	camelName := toCamelCase(modelName)
-	s = replaceProtoFieldAccess(s, "skeleton", camelName)
+	camelName := naming.Camel(modelName)
+	s = naming.ReplaceFieldAccess(s, "skeleton", camelName)
 	s = strings.ReplaceAll(s, "skeleton: {", camelName+": {")
 	s = strings.ReplaceAll(s, "skeleton", modelName)
 
@@ -273,7 +202,7 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	emptyBuilder.WriteString(emptyIndent + "updated: '',\n")
 	emptyBuilder.WriteString(emptyIndent + "id: '',\n")
 	for _, c := range columns {
-		field := toCamelCase(c.Name)
+		field := naming.Camel(c.Name)
 		if c.Type == "bool" {
 			emptyBuilder.WriteString(emptyIndent + field + ": false,\n")
 			continue
@@ -285,7 +214,7 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	var formDataBuilder strings.Builder
 	formDataIndent := "    "
 	for _, c := range columns {
-		field := toCamelCase(c.Name)
+		field := naming.Camel(c.Name)
 		if c.Type == "bool" {
 			formDataBuilder.WriteString(formDataIndent + "const " + field + " = formData.get('" + c.Name + "') === 'on'\n")
 			continue
@@ -296,26 +225,15 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 
 	var payloadBuilder strings.Builder
 	for _, c := range columns {
-		field := toCamelCase(c.Name)
+		field := naming.Camel(c.Name)
 		payloadBuilder.WriteString("            " + field + ",\n")
 	}
 	payloadSnippet := strings.TrimRight(payloadBuilder.String(), "\n")
 
-	toTitle := func(name string) string {
-		parts := strings.Split(name, "_")
-		for i := range parts {
-			if parts[i] == "" {
-				continue
-			}
-			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
-		}
-		return strings.Join(parts, " ")
-	}
-
 	var fieldsBuilder strings.Builder
 	for _, c := range columns {
-		label := toTitle(c.Name)
-		field := toCamelCase(c.Name)
+		label := naming.Title(c.Name)
+		field := naming.Camel(c.Name)
 		switch c.Type {
 		case "string":
 			fieldsBuilder.WriteString("          <label className=\"label\" htmlFor=\"" + c.Name + "\">\n")
@@ -377,33 +295,24 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 	}
 	fieldsSnippet := strings.TrimRight(fieldsBuilder.String(), "\n")
 
-	replaceRegion := func(content, startMarker, endMarker, replacement string) (string, error) {
-		start := strings.Index(content, startMarker)
-		end := strings.Index(content, endMarker)
-		if start == -1 || end == -1 || end < start {
-			return content, fmt.Errorf("markers %q .. %q not found", startMarker, endMarker)
-		}
-		return content[:start] + replacement + "\n" + content[end+len(endMarker):], nil
-	}
-
 	var replaceErr error
-	s, replaceErr = replaceRegion(s, "// GF_DETAIL_EMPTY_START", "// GF_DETAIL_EMPTY_END", emptySnippet)
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EMPTY", emptySnippet)
 	if replaceErr != nil {
 		return fmt.Errorf("replacing empty defaults: %w", replaceErr)
 	}
-	s, replaceErr = replaceRegion(s, "// GF_DETAIL_FORMDATA_START", "// GF_DETAIL_FORMDATA_END", formDataSnippet)
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FORMDATA", formDataSnippet)
 	if replaceErr != nil {
 		return fmt.Errorf("replacing form data: %w", replaceErr)
 	}
-	s, replaceErr = replaceRegion(s, "// GF_DETAIL_CREATE_FIELDS_START", "// GF_DETAIL_CREATE_FIELDS_END", payloadSnippet)
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_CREATE_FIELDS", payloadSnippet)
 	if replaceErr != nil {
 		return fmt.Errorf("replacing create fields: %w", replaceErr)
 	}
-	s, replaceErr = replaceRegion(s, "// GF_DETAIL_EDIT_FIELDS_START", "// GF_DETAIL_EDIT_FIELDS_END", payloadSnippet)
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_EDIT_FIELDS", payloadSnippet)
 	if replaceErr != nil {
 		return fmt.Errorf("replacing edit fields: %w", replaceErr)
 	}
-	s, replaceErr = replaceRegion(s, "{/* GF_DETAIL_FIELDS_START */}", "{/* GF_DETAIL_FIELDS_END */}", fieldsSnippet)
+	s, replaceErr = markers.ReplaceRequired(s, markers.StylesFor(sourcePath), "DETAIL_FIELDS", fieldsSnippet)
 	if replaceErr != nil {
 		return fmt.Errorf("replacing UI fields: %w", replaceErr)
 	}
@@ -416,24 +325,11 @@ func generateClientDetailPage(modelName string, columns []Column) error {
 		}
 	}
 
-	markers := []string{
-		"// GF_DETAIL_EMPTY_START", "// GF_DETAIL_EMPTY_END",
-		"// GF_DETAIL_FORMDATA_START", "// GF_DETAIL_FORMDATA_END",
-		"// GF_DETAIL_CREATE_FIELDS_START", "// GF_DETAIL_CREATE_FIELDS_END",
-		"// GF_DETAIL_EDIT_FIELDS_START", "// GF_DETAIL_EDIT_FIELDS_END",
-		"{/* GF_DETAIL_FIELDS_START */}", "{/* GF_DETAIL_FIELDS_END */}",
-	}
 	var outLines []string
 	inFormatDateFunc := false
 	braceDepth := 0
 	for line := range strings.SplitSeq(s, "\n") {
 		skip := false
-		for _, marker := range markers {
-			if strings.Contains(line, marker) {
-				skip = true
-				break
-			}
-		}
 		if !hasDateColumn {
 			trimmed := strings.TrimSpace(line)
 			if strings.HasPrefix(trimmed, "function formatDate(") {